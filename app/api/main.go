@@ -2,21 +2,32 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"expvar"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/Yeremi528/laboratorio/app/api/v1/build/all"
 	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	v1 "github.com/Yeremi528/laboratorio/business/web"
+	"github.com/Yeremi528/laboratorio/business/web/auth"
 	"github.com/Yeremi528/laboratorio/business/web/debug"
+	"github.com/Yeremi528/laboratorio/business/web/health"
+	"github.com/Yeremi528/laboratorio/foundation/keystore"
 	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/otel"
 	"github.com/Yeremi528/laboratorio/foundation/web"
 	"github.com/ardanlabs/conf/v3"
+	"github.com/jmoiron/sqlx"
 )
 
 var build = "dev"
@@ -47,24 +58,41 @@ func main() {
 		logLevel = logger.LevelInfo
 	}
 
-	traceFunc := func(ctx context.Context) []any {
-		v := web.GetValues(ctx)
-
-		fields := make([]any, 2, 4)
-		fields[0], fields[1] = "traceID", v.TraceID
+	// The text format is easier to read while developing locally.
+	newLogger := logger.New
+	if os.Getenv("APP_LOG_FORMAT") == "text" {
+		newLogger = logger.NewText
+	}
 
-		return fields
+	// The records can be written from a background goroutine, blocking or
+	// dropping records when the queue is full, to keep the writes out of the
+	// request latency.
+	var out io.Writer = os.Stdout
+	closeLog := func() error { return nil }
+
+	switch os.Getenv("APP_LOG_ASYNC") {
+	case "block":
+		aw := logger.NewAsyncWriter(os.Stdout, 4096, logger.OverflowBlock)
+		out, closeLog = aw, aw.Close
+	case "drop":
+		aw := logger.NewAsyncWriter(os.Stdout, 4096, logger.OverflowDrop)
+		out, closeLog = aw, aw.Close
 	}
 
-	log := logger.New(os.Stdout, logLevel, "go-ms-laboratorio", traceFunc)
+	log := newLogger(out, logLevel, "go-ms-laboratorio", web.TraceFields).WithRedaction(logger.SensitiveKeys)
 
 	ctx := context.Background()
 
+	// The queued records must be written before exiting, os.Exit doesn't
+	// run the deferred functions.
 	if err := run(ctx, log); err != nil {
 		log.Error(ctx, "startup", "msg", err)
+		closeLog()
 		os.Exit(1)
 	}
 
+	closeLog()
+
 }
 
 func run(ctx context.Context, log *logger.Logger) error {
@@ -78,29 +106,13 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	cfg := struct {
 		conf.Version
-		Web struct {
-			ReadTimeout        time.Duration `conf:"default:5s"`
-			WriteTimeout       time.Duration `conf:"default:10s"`
-			IdleTimeout        time.Duration `conf:"default:120s"`
-			ShutdownTimeout    time.Duration `conf:"default:20s"`
-			APIHost            string        `conf:"default:0.0.0.0:3000"`
-			DebugHost          string        `conf:"default:0.0.0.0:4000"`
-			CORSAllowedOrigins []string      `conf:"default:*"`
-		}
+		Web  webConfig
 		Auth struct {
 			KeysFolder string `conf:"default:zarf/keys/"`
 			ActiveKID  string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
 			Issuer     string `conf:"default:service project"`
 		}
-		DB struct {
-			User         string `conf:"default:postgres"`
-			Password     string `conf:"default:julia123,mask"`
-			HostPort     string `conf:"default:35.192.78.50"`
-			Name         string `conf:"default:postgres"`
-			MaxIdleConns int    `conf:"default:2"`
-			MaxOpenConns int    `conf:"default:0"`
-			DisableTLS   bool   `conf:"default:true"`
-		}
+		DB    dbConfig
 		Tempo struct {
 			ReporterURI string  `conf:"default:tempo.sales-system.svc.cluster.local:4317"`
 			ServiceName string  `conf:"default:sales-api"`
@@ -140,38 +152,104 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	expvar.NewString("build").Set(build)
 
+	info := debug.Info()
+	log.Info(ctx, "startup", "build", info.Build, "goversion", info.GoVersion, "gomaxprocs", info.GOMAXPROCS)
+
 	// -------------------------------------------------------------------------
 	// Database Support
 
 	log.Info(ctx, "startup", "status", "initializing database support", "hostport", cfg.DB.HostPort)
-	db, err := pgx.Open(pgx.Config{
-		User:     cfg.DB.User,
-		Password: cfg.DB.Password,
-		Host:     cfg.DB.HostPort,
-		Name:     cfg.DB.Name,
-
-		MaxIdleConns:    cfg.DB.MaxIdleConns,
-		MaxOpenConns:    cfg.DB.MaxOpenConns,
-		Port:            "5432",
-		IdleConnTimeout: 30,
-
-		ApplicationName: "onboarding/go-ms-enrollment-finalize",
-	})
+	db, err := pgx.Open(cfg.DB.pgxConfig())
 	if err != nil {
 		return fmt.Errorf("connecting to db: %w", err)
 	}
-	defer func() {
+
+	// The database is closed last when shutting down, the deferred call
+	// covers the startup and server errors.
+	var replicas []*sqlx.DB
+	closeDB := sync.OnceFunc(func() {
 		log.Info(ctx, "shutdown", "status", "stopping database support", "hostport", cfg.DB.HostPort)
+		for _, replica := range replicas {
+			replica.Close()
+		}
 		db.Close()
-	}()
+	})
+	defer closeDB()
+
+	// The reads of the users go to the read replicas when configured, the
+	// failing ones are taken out by a periodic check.
+	var dbReplicas *pgx.DB
+	if len(cfg.DB.ReplicaHostPorts) > 0 {
+		log.Info(ctx, "startup", "status", "initializing database replicas", "hostports", cfg.DB.ReplicaHostPorts)
+
+		for _, pcfg := range cfg.DB.replicaConfigs() {
+			replica, err := pgx.Open(pcfg)
+			if err != nil {
+				return fmt.Errorf("connecting to db replica %s: %w", pcfg.Host, err)
+			}
+			replicas = append(replicas, replica)
+		}
+
+		dbReplicas = pgx.NewDB(db, replicas...)
+
+		checkCtx, stopChecks := context.WithCancel(ctx)
+		defer stopChecks()
+
+		dbReplicas.Start(checkCtx, cfg.DB.ReplicaCheckInterval)
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize authentication support
+
+	log.Info(ctx, "startup", "status", "initializing authentication support")
+
+	auth, err := newAuth(log, cfg.Auth.KeysFolder, cfg.Auth.ActiveKID, cfg.Auth.Issuer)
+	if err != nil {
+		return err
+	}
+
+	// -------------------------------------------------------------------------
+	// Start Tracing Support
+
+	log.Info(ctx, "startup", "status", "initializing OT/Tempo tracing support")
+
+	shutdownTracing, err := otel.StartTracing(otel.Config{
+		ServiceName: cfg.Tempo.ServiceName,
+		ReporterURI: cfg.Tempo.ReporterURI,
+		Probability: cfg.Tempo.Probability,
+	})
+	if err != nil {
+		return fmt.Errorf("starting tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	tracer := otel.Tracer(cfg.Tempo.ServiceName)
 
 	// -------------------------------------------------------------------------
 	// Start Debug Service
 
+	debugMux := debug.Mux()
+	debugMux.Handle("/debug/loglevel", debug.LogLevel(log))
+	checks := health.New(time.Second, health.NewDBChecker("db", db))
+	debugMux.Handle("/debug/readiness", checks.Handler())
+
+	// Sample payloads come from real requests, only capture them in
+	// development.
+	var examples *debug.Examples
+	if cfg.Web.CaptureExamples {
+		examples = debug.NewExamples()
+		debugMux.Handle("/debug/examples", examples)
+	}
+
+	debugSrv := http.Server{
+		Addr:    cfg.Web.DebugHost,
+		Handler: debugMux,
+	}
+
 	go func() {
 		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
 
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
+		if err := debugSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
 		}
 	}()
@@ -184,15 +262,39 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
-	api := http.Server{
-		Addr:         cfg.Web.APIHost,
-		Handler:      debug.Mux(),
-		ReadTimeout:  cfg.Web.ReadTimeout,
-		WriteTimeout: cfg.Web.WriteTimeout,
-		IdleTimeout:  cfg.Web.IdleTimeout,
-		ErrorLog:     logger.NewStdLogger(log, logger.LevelError),
+	cfgMux := v1.APIMuxConfig{
+		Build:              build,
+		Shutdown:           shutdown,
+		Log:                log,
+		Auth:               auth,
+		DB:                 db,
+		Replicas:           dbReplicas,
+		Tracer:             tracer,
+		MaxBodyBytes:       cfg.Web.MaxBodyBytes,
+		MaxInFlight:        cfg.Web.MaxInFlight,
+		MaxQueryParams:     cfg.Web.MaxQueryParams,
+		Audit:              cfg.Web.Audit,
+		CORSAllowedOrigins: cfg.Web.CORSAllowedOrigins,
+		Compression: web.Compression{
+			Enabled:  cfg.Web.Compress,
+			MinBytes: cfg.Web.CompressMinBytes,
+		},
+		Examples: examples,
 	}
 
+	// Legacy clients asking for XML in the Accept header get it, everyone
+	// else keeps getting JSON.
+	if cfg.Web.XMLResponses {
+		cfgMux.Formats = []string{web.ContentTypeXML}
+	}
+	apiMux := v1.APIMux(cfgMux, all.Routes())
+
+	// The readiness checks of the debug router fail once the shutdown
+	// starts draining the API.
+	checks.Register(health.NewDrainChecker("api", apiMux.Draining))
+
+	api := newAPIServer(ctx, log, cfg.Web, apiMux)
+
 	serverErrors := make(chan error, 1)
 
 	go func() {
@@ -212,11 +314,26 @@ func run(ctx context.Context, log *logger.Logger) error {
 		log.Info(ctx, "shutdown", "status", "shutdown started", "signal", sig)
 		defer log.Info(ctx, "shutdown", "status", "shutdown complete", "signal", sig)
 
-		ctx, cancel := context.WithTimeout(ctx, cfg.Web.ShutdownTimeout)
-		defer cancel()
+		hooks := shutdownHooks{
+			Drain:      apiMux.Drain,
+			Sleep:      time.Sleep,
+			StopServer: api.Shutdown,
+			InFlight:   apiMux.InFlight,
+			DBStats: func() sql.DBStats {
+				return pgx.Stats(db)
+			},
+			CloseServer: api.Close,
+			StopDebug: func(ctx context.Context) error {
+				if err := debugSrv.Shutdown(ctx); err != nil {
+					debugSrv.Close()
+					return err
+				}
+				return nil
+			},
+			CloseDB: closeDB,
+		}
 
-		if err := api.Shutdown(ctx); err != nil {
-			api.Close()
+		if err := gracefulShutdown(ctx, log, hooks, cfg.Web.ShutdownGrace, cfg.Web.ShutdownTimeout); err != nil {
 			return fmt.Errorf("could not stop server gracefully: %w", err)
 		}
 	}
@@ -224,3 +341,216 @@ func run(ctx context.Context, log *logger.Logger) error {
 	return nil
 
 }
+
+// dbConfig holds the settings of the database connection.
+type dbConfig struct {
+	User         string        `conf:"default:postgres"`
+	Password     string        `conf:"default:julia123,mask"`
+	HostPort     string        `conf:"default:35.192.78.50"`
+	Name         string        `conf:"default:postgres"`
+	MaxIdleConns int           `conf:"default:2"`
+	MaxOpenConns int           `conf:"default:0"`
+	DisableTLS   bool          `conf:"default:true"`
+	IdleTimeout  time.Duration `conf:"default:30s"`
+
+	// ReplicaHostPorts lists the read replicas of the database, they use
+	// the same credentials as the primary.
+	ReplicaHostPorts     []string
+	ReplicaCheckInterval time.Duration `conf:"default:10s"`
+}
+
+// defaultDBPort is the port used when HostPort doesn't have one.
+const defaultDBPort = "5432"
+
+// pgxConfig returns the configuration used to open the database. HostPort
+// can leave out the port to use the default one.
+func (cfg dbConfig) pgxConfig() pgx.Config {
+	return cfg.hostConfig(cfg.HostPort)
+}
+
+// replicaConfigs returns the configurations used to open the read replicas.
+func (cfg dbConfig) replicaConfigs() []pgx.Config {
+	cfgs := make([]pgx.Config, len(cfg.ReplicaHostPorts))
+	for i, hostPort := range cfg.ReplicaHostPorts {
+		cfgs[i] = cfg.hostConfig(hostPort)
+	}
+
+	return cfgs
+}
+
+// hostConfig returns the configuration used to open the database at
+// hostPort.
+func (cfg dbConfig) hostConfig(hostPort string) pgx.Config {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host, port = hostPort, defaultDBPort
+	}
+
+	return pgx.Config{
+		User:     cfg.User,
+		Password: cfg.Password,
+		Host:     host,
+		Port:     port,
+		Name:     cfg.Name,
+
+		MaxIdleConns:    cfg.MaxIdleConns,
+		MaxOpenConns:    cfg.MaxOpenConns,
+		IdleConnTimeout: cfg.IdleTimeout,
+
+		ApplicationName: "onboarding/go-ms-enrollment-finalize",
+	}
+}
+
+// webConfig holds the settings of the api and debug routers.
+type webConfig struct {
+	ReadTimeout        time.Duration `conf:"default:5s"`
+	WriteTimeout       time.Duration `conf:"default:10s"`
+	IdleTimeout        time.Duration `conf:"default:120s"`
+	ShutdownTimeout    time.Duration `conf:"default:20s"`
+	ShutdownGrace      time.Duration `conf:"default:5s"`
+	APIHost            string        `conf:"default:0.0.0.0:3000"`
+	DebugHost          string        `conf:"default:0.0.0.0:4000"`
+	CORSAllowedOrigins []string      `conf:"default:*"`
+	LogConnState       bool          `conf:"default:false"`
+	Compress           bool          `conf:"default:true"`
+	CompressMinBytes   int           `conf:"default:1024"`
+	CaptureExamples    bool          `conf:"default:false"`
+	MaxBodyBytes       int64         `conf:"default:1048576"`
+	MaxInFlight        int           `conf:"default:0"`
+	MaxQueryParams     int           `conf:"default:50"`
+	XMLResponses       bool          `conf:"default:false"`
+	Audit              bool          `conf:"default:true"`
+}
+
+// newAPIServer constructs the server of the api router on its own host,
+// the debug router is served apart on the DebugHost.
+func newAPIServer(ctx context.Context, log *logger.Logger, cfg webConfig, handler http.Handler) *http.Server {
+	api := http.Server{
+		Addr:         cfg.APIHost,
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		ErrorLog:     logger.NewMaskedStdLogger(log, logger.LevelError),
+	}
+
+	if cfg.LogConnState {
+		api.ConnState = connStateLogger(ctx, log)
+	}
+
+	return &api
+}
+
+// newAuth constructs the authentication support with the keys found in the
+// folder. The default folder holds a development key, see zarf/keys.
+func newAuth(log *logger.Logger, keysFolder string, activeKID string, issuer string) (*auth.Auth, error) {
+
+	// Simple keystore versus using Vault.
+	ks, err := keystore.NewFS(os.DirFS(keysFolder))
+	if err != nil {
+		return nil, fmt.Errorf("reading keys: %w", err)
+	}
+
+	authCfg := auth.Config{
+		Log:       log,
+		KeyLookup: ks,
+		ActiveKID: activeKID,
+		Issuer:    issuer,
+	}
+
+	a, err := auth.New(authCfg)
+	if err != nil {
+		return nil, fmt.Errorf("constructing auth: %w", err)
+	}
+
+	return a, nil
+}
+
+// debugShutdownTimeout is the time the debug router has to complete its
+// requests once the api router is stopped.
+const debugShutdownTimeout = 5 * time.Second
+
+// shutdownHooks holds the steps of the shutdown sequence.
+type shutdownHooks struct {
+	Drain       func()
+	Sleep       func(time.Duration)
+	StopServer  func(ctx context.Context) error
+	InFlight    func() int64
+	DBStats     func() sql.DBStats
+	CloseServer func() error
+	StopDebug   func(ctx context.Context) error
+	CloseDB     func()
+}
+
+// gracefulShutdown fails the readiness checks and gives the load balancer the grace
+// period to stop routing new requests before the server stops accepting them.
+// The server then has up to timeout to complete the in-flight requests, when
+// it runs out they are cut off, and their number is logged along with the
+// database connections in use to tell a slow handler from a stuck query. The
+// debug server is stopped next with its own timeout, it keeps serving the
+// failing readiness checks until then, and the database is closed last since
+// the handlers may still be using it.
+func gracefulShutdown(ctx context.Context, log *logger.Logger, hooks shutdownHooks, grace time.Duration, timeout time.Duration) error {
+	log.Info(ctx, "shutdown", "status", "draining", "grace", grace, "inflight", hooks.InFlight())
+	hooks.Drain()
+	hooks.Sleep(grace)
+
+	log.Info(ctx, "shutdown", "status", "stopping api router", "timeout", timeout, "inflight", hooks.InFlight())
+	apiCtx, apiCancel := context.WithTimeout(ctx, timeout)
+	defer apiCancel()
+
+	err := hooks.StopServer(apiCtx)
+	if err != nil {
+		stats := hooks.DBStats()
+		log.Warn(ctx, "shutdown", "status", "forcing api router to close", "inflight", hooks.InFlight(), "dbopen", stats.OpenConnections, "dbinuse", stats.InUse, "ERROR", err)
+		hooks.CloseServer()
+	}
+	log.Info(ctx, "shutdown", "status", "api router stopped")
+
+	// The debug router gets its own time, the one of the api router may
+	// have run out.
+	log.Info(ctx, "shutdown", "status", "stopping debug router")
+	debugCtx, debugCancel := context.WithTimeout(ctx, debugShutdownTimeout)
+	defer debugCancel()
+
+	if err := hooks.StopDebug(debugCtx); err != nil {
+		log.Warn(ctx, "shutdown", "status", "forcing debug router to close", "ERROR", err)
+	}
+
+	hooks.CloseDB()
+
+	return err
+}
+
+// connStateLogger returns a hook for http.Server.ConnState that logs every
+// connection lifecycle transition along with the number of open connections
+// in each state and the total of connections closed so far. It helps to tune
+// the server timeouts.
+func connStateLogger(ctx context.Context, log *logger.Logger) func(net.Conn, http.ConnState) {
+	var mu sync.Mutex
+	conns := make(map[net.Conn]http.ConnState)
+	counts := make(map[http.ConnState]int)
+
+	f := func(conn net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if prev, exists := conns[conn]; exists {
+			counts[prev]--
+		}
+
+		switch state {
+		case http.StateClosed, http.StateHijacked:
+			delete(conns, conn)
+		default:
+			conns[conn] = state
+		}
+		counts[state]++
+
+		log.Debug(ctx, "connection state", "remoteaddr", conn.RemoteAddr().String(), "state", state.String(),
+			"new", counts[http.StateNew], "active", counts[http.StateActive], "idle", counts[http.StateIdle],
+			"closedtotal", counts[http.StateClosed])
+	}
+
+	return f
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/app/api/v1/build/all"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	v1 "github.com/Yeremi528/laboratorio/business/web"
+	"github.com/Yeremi528/laboratorio/business/web/auth"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestConnStateLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelDebug, "test", nil)
+
+	hook := connStateLogger(context.Background(), log)
+
+	c1, s1 := net.Pipe()
+	defer c1.Close()
+	defer s1.Close()
+	c2, s2 := net.Pipe()
+	defer c2.Close()
+	defer s2.Close()
+
+	hook(s1, http.StateNew)
+	hook(s2, http.StateNew)
+	hook(s1, http.StateActive)
+	hook(s1, http.StateIdle)
+	hook(s2, http.StateClosed)
+	hook(s1, http.StateClosed)
+
+	type record struct {
+		State  string `json:"state"`
+		New    int    `json:"new"`
+		Active int    `json:"active"`
+		Idle   int    `json:"idle"`
+		Closed int    `json:"closedtotal"`
+	}
+
+	want := []record{
+		{State: "new", New: 1},
+		{State: "new", New: 2},
+		{State: "active", New: 1, Active: 1},
+		{State: "idle", New: 1, Idle: 1},
+		{State: "closed", Idle: 1, Closed: 1},
+		{State: "closed", Closed: 2},
+	}
+
+	dec := json.NewDecoder(&buf)
+	for i, w := range want {
+		var got struct {
+			CustomFields record `json:"customFields"`
+		}
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("record %d: decoding: %s", i, err)
+		}
+
+		if got.CustomFields != w {
+			t.Errorf("record %d: expected %+v, got %+v", i, w, got.CustomFields)
+		}
+	}
+}
+
+func TestShutdownOrder(t *testing.T) {
+	const (
+		grace   = 5 * time.Second
+		timeout = 20 * time.Second
+	)
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		stopErr  error
+		expected []string
+	}{
+		{name: "graceful", timeout: timeout, expected: []string{"drain", "sleep", "stop", "stopdebug", "closedb"}},
+		{name: "timeout", timeout: time.Millisecond, stopErr: context.DeadlineExceeded, expected: []string{"drain", "sleep", "stop", "close", "stopdebug", "closedb"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+			var steps []string
+
+			hooks := shutdownHooks{
+				Drain: func() {
+					steps = append(steps, "drain")
+				},
+				Sleep: func(d time.Duration) {
+					if d != grace {
+						t.Errorf("expected a grace of %s, got %s", grace, d)
+					}
+					steps = append(steps, "sleep")
+				},
+				StopServer: func(ctx context.Context) error {
+					if _, ok := ctx.Deadline(); !ok {
+						t.Error("expected the server to be stopped with a deadline")
+					}
+					steps = append(steps, "stop")
+					if tt.stopErr != nil {
+						<-ctx.Done()
+					}
+					return tt.stopErr
+				},
+				InFlight: func() int64 {
+					return 3
+				},
+				DBStats: func() sql.DBStats {
+					return sql.DBStats{OpenConnections: 4, InUse: 2}
+				},
+				CloseServer: func() error {
+					steps = append(steps, "close")
+					return nil
+				},
+				StopDebug: func(ctx context.Context) error {
+					if _, ok := ctx.Deadline(); !ok || ctx.Err() != nil {
+						t.Errorf("expected the debug router to get its own time, got %v", ctx.Err())
+					}
+					steps = append(steps, "stopdebug")
+					return nil
+				},
+				CloseDB: func() {
+					steps = append(steps, "closedb")
+				},
+			}
+
+			err := gracefulShutdown(context.Background(), log, hooks, grace, tt.timeout)
+			if !errors.Is(err, tt.stopErr) {
+				t.Fatalf("expected error %v, got %v", tt.stopErr, err)
+			}
+
+			if !slices.Equal(steps, tt.expected) {
+				t.Fatalf("expected steps %v, got %v", tt.expected, steps)
+			}
+
+			forced := strings.Contains(buf.String(), `"status":"forcing api router to close","inflight":3,"dbopen":4,"dbinuse":2`)
+			if forced != (tt.stopErr != nil) {
+				t.Fatalf("expected the requests cut off to be logged only when forced: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestNewAuthDefaultKeys(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "test", nil)
+
+	// The defaults of the Auth config, relative to the root of the repo where
+	// the service runs from.
+	a, err := newAuth(log, "../../zarf/keys/", "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1", "service project")
+	if err != nil {
+		t.Fatalf("expected the service to start with the default keys: %s", err)
+	}
+
+	token, err := a.GenerateToken(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "12345678-5",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	if _, err := a.Authenticate(context.Background(), "Bearer "+token); err != nil {
+		t.Fatalf("expected the token to be valid: %s", err)
+	}
+}
+
+func TestAPIServer(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	a, err := newAuth(log, "../../zarf/keys/", "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1", "service project")
+	if err != nil {
+		t.Fatalf("constructing auth: %s", err)
+	}
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("creating sqlmock: %s", err)
+	}
+	defer db.Close()
+
+	apiMux := v1.APIMux(v1.APIMuxConfig{
+		Build:          "test",
+		Shutdown:       make(chan os.Signal, 1),
+		Log:            log,
+		Auth:           a,
+		DB:             sqlx.NewDb(db, "pgx"),
+		MaxQueryParams: 2,
+	}, all.Routes())
+
+	cfg := webConfig{
+		APIHost:      "127.0.0.1:0",
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  2 * time.Minute,
+	}
+
+	api := newAPIServer(context.Background(), log, cfg, apiMux)
+
+	if api.Addr != cfg.APIHost || api.ReadTimeout != cfg.ReadTimeout || api.WriteTimeout != cfg.WriteTimeout || api.IdleTimeout != cfg.IdleTimeout {
+		t.Fatalf("expected the server to use the web config, got %+v", api)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "liveness", path: "/liveness", status: http.StatusOK},
+		{name: "users behind auth", path: "/v1/users", status: http.StatusUnauthorized},
+		{name: "debug routes apart", path: "/debug/pprof/", status: http.StatusNotFound},
+		{name: "too many query params", path: "/v1/users?a=1&b=2&c=3", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			api.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestDBConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostPort string
+		host     string
+		port     string
+	}{
+		{name: "host", hostPort: "db.example.com", host: "db.example.com", port: "5432"},
+		{name: "host and port", hostPort: "db.example.com:6543", host: "db.example.com", port: "6543"},
+		{name: "ipv6", hostPort: "[::1]:6543", host: "::1", port: "6543"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := dbConfig{HostPort: tt.hostPort, IdleTimeout: 30 * time.Second}.pgxConfig()
+
+			if cfg.Host != tt.host || cfg.Port != tt.port {
+				t.Fatalf("expected %s and %s, got %s and %s", tt.host, tt.port, cfg.Host, cfg.Port)
+			}
+
+			if cfg.IdleConnTimeout != 30*time.Second {
+				t.Fatalf("expected the idle timeout of the config, got %s", cfg.IdleConnTimeout)
+			}
+		})
+	}
+}
+
+func TestDBReplicaConfigs(t *testing.T) {
+	cfg := dbConfig{
+		User:             "postgres",
+		HostPort:         "db.example.com",
+		ReplicaHostPorts: []string{"replica-1.example.com", "replica-2.example.com:6543"},
+	}
+
+	replicas := cfg.replicaConfigs()
+	if len(replicas) != 2 {
+		t.Fatalf("expected 2 replicas, got %d", len(replicas))
+	}
+
+	if replicas[0].Host != "replica-1.example.com" || replicas[0].Port != defaultDBPort || replicas[1].Port != "6543" {
+		t.Fatalf("expected the hosts of the replicas, got %+v", replicas)
+	}
+
+	if replicas[1].User != cfg.User {
+		t.Fatalf("expected the replicas to use the credentials of the primary, got %q", replicas[1].User)
+	}
+}
+
+func TestUserRoutesUseDB(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	a, err := newAuth(log, "../../zarf/keys/", "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1", "service project")
+	if err != nil {
+		t.Fatalf("constructing auth: %s", err)
+	}
+
+	token, err := a.GenerateToken(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "12345678-5",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: []string{"ADMIN"},
+	})
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	newDB := func(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("creating sqlmock: %s", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		return sqlx.NewDb(db, "pgx"), mock
+	}
+
+	tests := []struct {
+		name     string
+		replicas bool
+	}{
+		{name: "primary"},
+		{name: "replica", replicas: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, primaryMock := newDB(t)
+
+			cfg := v1.APIMuxConfig{
+				Build:    "test",
+				Shutdown: make(chan os.Signal, 1),
+				Log:      log,
+				Auth:     a,
+				DB:       primary,
+			}
+
+			mock := primaryMock
+			if tt.replicas {
+				var replica *sqlx.DB
+				replica, mock = newDB(t)
+				cfg.Replicas = pgx.NewDB(primary, replica)
+			}
+
+			apiMux := v1.APIMux(cfg, all.Routes())
+
+			columns := []string{"user_id", "name", "email", "rut", "roles", "password_hash", "department", "enabled", "date_created", "date_updated", "deleted_at"}
+			now := time.Now()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("set_config").WithArgs("app.current_rut", "12345678-5").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`FROM\s+users`).WillReturnRows(sqlmock.NewRows(columns).AddRow("5cf37266-3473-4006-984f-9325122678b7", "Ana Rojas", "ana@example.com", "12345678-5", "{ADMIN}", "hash", nil, true, now, now, nil))
+			mock.ExpectQuery(`count\(1\)`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectCommit()
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			apiMux.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body)
+			}
+
+			if !strings.Contains(w.Body.String(), "Ana Rojas") {
+				t.Fatalf("expected the users of the database, got %s", w.Body)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("expected the handlers to use the configured database: %s", err)
+			}
+
+			if err := primaryMock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("expected no unexpected query on the primary: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Package all binds all the routes into the specified app.
+package all
+
+import (
+	"github.com/Yeremi528/laboratorio/app/api/v1/handlers/checkgrp"
+	"github.com/Yeremi528/laboratorio/app/api/v1/handlers/usergrp"
+	v1 "github.com/Yeremi528/laboratorio/business/web"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// Routes constructs the add value which provides the implementation
+// of RouteAdder for specifying what routes to bind to this instance.
+func Routes() add {
+	return add{}
+}
+
+type add struct{}
+
+// Add implements the RouterAdder interface.
+func (add) Add(app *web.App, cfg v1.APIMuxConfig) {
+	checkgrp.Routes(app, checkgrp.Config{
+		Build: cfg.Build,
+		Log:   cfg.Log,
+		DB:    cfg.DB,
+	})
+
+	usergrp.Routes(app, usergrp.Config{
+		Log:      cfg.Log,
+		Auth:     cfg.Auth,
+		DB:       cfg.DB,
+		Replicas: cfg.Replicas,
+	})
+}
@@ -0,0 +1,95 @@
+// Package checkgrp maintains the group of handlers for health checking.
+package checkgrp
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/jmoiron/sqlx"
+)
+
+// readinessTimeout is the time the database has to answer a readiness check.
+const readinessTimeout = time.Second
+
+// Handlers manages the set of check endpoints.
+type Handlers struct {
+	build    string
+	log      *logger.Logger
+	db       *sqlx.DB
+	draining func() bool
+}
+
+// New constructs a Handlers api for the check group.
+func New(build string, log *logger.Logger, db *sqlx.DB, draining func() bool) *Handlers {
+	return &Handlers{
+		build:    build,
+		log:      log,
+		db:       db,
+		draining: draining,
+	}
+}
+
+// Info represents the state of the service reported by the checks.
+type Info struct {
+	Status string `json:"status"`
+	Build  string `json:"build"`
+	Host   string `json:"host"`
+	DB     string `json:"db,omitempty"`
+}
+
+// Readiness checks if the database is ready and the service is not draining,
+// so it can receive traffic. A 503 is returned otherwise.
+func (h *Handlers) Readiness(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unavailable"
+	}
+
+	info := Info{
+		Status: "ok",
+		Build:  h.build,
+		Host:   host,
+		DB:     "ok",
+	}
+	statusCode := http.StatusOK
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	// The cause is only logged, it can tell the address of the database.
+	if err := pgx.StatusCheck(ctx, h.db); err != nil {
+		h.log.Info(ctx, "readiness failure", "ERROR", err)
+		info.Status = "db not ready"
+		info.DB = "not ready"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	if h.draining != nil && h.draining() {
+		info.Status = "draining"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	return web.Respond(ctx, w, info, statusCode)
+}
+
+// Liveness returns simple status info if the service is alive. It answers
+// with a 200 as long as the service is running.
+func (h *Handlers) Liveness(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unavailable"
+	}
+
+	info := Info{
+		Status: "up",
+		Build:  h.build,
+		Host:   host,
+	}
+
+	return web.Respond(ctx, w, info, http.StatusOK)
+}
@@ -0,0 +1,82 @@
+package checkgrp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/app/api/v1/handlers/checkgrp"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbErr    error
+		draining bool
+		status   int
+		info     checkgrp.Info
+	}{
+		{name: "ready", status: http.StatusOK, info: checkgrp.Info{Status: "ok", DB: "ok"}},
+		{name: "db down", dbErr: errors.New("dial tcp 10.0.0.5:5432: connection refused"), status: http.StatusServiceUnavailable, info: checkgrp.Info{Status: "db not ready", DB: "not ready"}},
+		{name: "draining", draining: true, status: http.StatusServiceUnavailable, info: checkgrp.Info{Status: "draining", DB: "ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("creating sqlmock: %s", err)
+			}
+			defer db.Close()
+
+			exp := mock.ExpectQuery("SELECT true")
+			if tt.dbErr != nil {
+				exp.WillReturnError(tt.dbErr)
+			} else {
+				exp.WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+			}
+
+			var logs bytes.Buffer
+			log := logger.New(&logs, logger.LevelInfo, "test", nil)
+
+			hdl := checkgrp.New("test", log, sqlx.NewDb(db, "pgx"), func() bool { return tt.draining })
+
+			app := web.NewApp(nil)
+			app.CustomHandle(http.MethodGet, "", "/readiness", hdl.Readiness)
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+
+			var info checkgrp.Info
+			if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+				t.Fatalf("decoding body: %s", err)
+			}
+
+			if info.Status != tt.info.Status || info.DB != tt.info.DB {
+				t.Fatalf("expected status %q and db %q, got %+v", tt.info.Status, tt.info.DB, info)
+			}
+
+			if tt.dbErr != nil {
+				if strings.Contains(w.Body.String(), "10.0.0.5") {
+					t.Fatalf("expected the cause to be left out of the response, got %s", w.Body)
+				}
+
+				if !strings.Contains(logs.String(), "10.0.0.5") {
+					t.Fatalf("expected the cause to be logged, got %s", logs.String())
+				}
+			}
+		})
+	}
+}
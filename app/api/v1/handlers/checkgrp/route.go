@@ -0,0 +1,24 @@
+package checkgrp
+
+import (
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/jmoiron/sqlx"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Build string
+	Log   *logger.Logger
+	DB    *sqlx.DB
+}
+
+// Routes adds specific routes for this group. The checks skip the app
+// middleware so they don't flood the logs and metrics.
+func Routes(app *web.App, cfg Config) {
+	hdl := New(cfg.Build, cfg.Log, cfg.DB, app.Draining)
+	app.CustomHandle(http.MethodGet, "", "/readiness", hdl.Readiness)
+	app.CustomHandle(http.MethodGet, "", "/liveness", hdl.Liveness)
+}
@@ -1,24 +1,37 @@
 package usergrp
 
 import (
+	"net/http"
+
 	"github.com/Yeremi528/laboratorio/business/core/user"
-	"github.com/Yeremi528/laboratorio/foundation/logger"
-	"github.com/Yeremi528/laboratorio/foundation/web"
-	"github.com/jmoiron/sqlx"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/google/uuid"
 )
 
-// Config contains all the mandatory systems required by handlers.
-type Config struct {
-	Log *logger.Logger
-	DB  *sqlx.DB
-}
+func parseFilter(r *http.Request) (user.QueryFilter, error) {
+	values := r.URL.Query()
+
+	var filter user.QueryFilter
+
+	if userID := values.Get("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError("user_id", err)
+		}
+		filter.ID = &id
+	}
+
+	if name := values.Get("name"); name != "" {
+		filter.Name = &name
+	}
 
-// Route adds specific routes for this group.
-func Routes(app *web.App, cfg Config) {
-	const version = "v1"
-	usrCore := user.NewCore(cfg.Log, cfg.DB)
+	if email := values.Get("email"); email != "" {
+		filter.Email = &email
+	}
 
-	New(usrCore)
-	//app.Handle(http.MethodGet, version, "/users", hdl.query)
+	if rut := values.Get("rut"); rut != "" {
+		filter.RUT = &rut
+	}
 
+	return filter, nil
 }
@@ -1 +1,62 @@
 package usergrp
+
+import (
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// AppUser represents information about an individual user.
+type AppUser struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Email       string   `json:"email" mask:"email"`
+	RUT         string   `json:"rut" mask:"rut"`
+	Roles       []string `json:"roles"`
+	Department  string   `json:"department"`
+	Enabled     bool     `json:"enabled"`
+	DateCreated web.Time `json:"dateCreated"`
+	DateUpdated web.Time `json:"dateUpdated"`
+}
+
+func toAppUser(usr user.User) AppUser {
+	return AppUser{
+		ID:          usr.ID.String(),
+		Name:        usr.Name,
+		Email:       usr.Email,
+		RUT:         usr.RUT,
+		Roles:       usr.Roles,
+		Department:  usr.Department,
+		Enabled:     usr.Enabled,
+		DateCreated: web.NewTime(usr.DateCreated),
+		DateUpdated: web.NewTime(usr.DateUpdated),
+	}
+}
+
+func toAppUsers(users []user.User) []AppUser {
+	items := make([]AppUser, len(users))
+	for i, usr := range users {
+		items[i] = toAppUser(usr)
+	}
+
+	return items
+}
+
+// AppNewUser contains information needed to create a new user.
+type AppNewUser struct {
+	Name       string `json:"name" validate:"required"`
+	Email      string `json:"email" validate:"required,email" mask:"email"`
+	RUT        string `json:"rut" validate:"required" mask:"rut"`
+	Department string `json:"department"`
+	Password   string `json:"password" validate:"required,min=8" mask:"filled"`
+}
+
+func toCoreNewUser(app AppNewUser) user.NewUser {
+	return user.NewUser{
+		Name:       app.Name,
+		Email:      app.Email,
+		RUT:        app.RUT,
+		Roles:      []string{user.RoleUser},
+		Department: app.Department,
+		Password:   app.Password,
+	}
+}
@@ -1 +1,32 @@
 package usergrp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/business/data/order"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+)
+
+var orderByFields = map[string]string{
+	"user_id": user.OrderByID,
+	"name":    user.OrderByName,
+	"email":   user.OrderByEmail,
+	"roles":   user.OrderByRoles,
+	"enabled": user.OrderByEnabled,
+}
+
+func parseOrder(r *http.Request) (order.By, error) {
+	orderBy, err := order.Parse(r, user.DefaultOrderBy)
+	if err != nil {
+		return order.By{}, err
+	}
+
+	field, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
+	}
+
+	return order.NewBy(field, orderBy.Direction), nil
+}
@@ -1,7 +1,49 @@
 package usergrp
 
-import "github.com/Yeremi528/laboratorio/business/core/user"
+import (
+	"net/http"
 
-func New(*user.Core) {
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/Yeremi528/laboratorio/business/web/auth"
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/jmoiron/sqlx"
+)
 
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log  *logger.Logger
+	Auth *auth.Auth
+	DB   *sqlx.DB
+
+	// Replicas sends the reads to the read replicas of DB when set.
+	Replicas *pgx.DB
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	usrCore := user.NewCore(cfg.Log, cfg.DB)
+
+	// The queries run within a transaction so the row level security
+	// policies can read the user's RUT set on it.
+	tran := mid.ExecuteInTransation(cfg.Log, pgx.NewBeginner(cfg.DB))
+
+	// The routes that only read begin their transaction on a replica.
+	readTran := tran
+	if cfg.Replicas != nil {
+		usrCore = user.NewCoreWithReplicas(cfg.Log, cfg.Replicas)
+		readTran = mid.ExecuteInTransation(cfg.Log, pgx.NewReadBeginner(cfg.Replicas))
+	}
+
+	authen := mid.Authenticate(cfg.Auth)
+	ruleAdmin := mid.Authorize(user.RoleAdmin)
+
+	hdl := New(usrCore)
+	app.Handle(http.MethodGet, version, "/users", hdl.Query, authen, ruleAdmin, readTran)
+	app.Handle(http.MethodGet, version, "/users/{user_id}", hdl.QueryByID, authen, readTran)
+	app.Handle(http.MethodPost, version, "/users", hdl.Create, authen, ruleAdmin, tran)
 }
@@ -1 +1,164 @@
+// Package usergrp maintains the group of handlers for user access.
 package usergrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/business/data/page"
+	"github.com/Yeremi528/laboratorio/business/data/transaction"
+	"github.com/Yeremi528/laboratorio/business/web/auth"
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/rut"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for handling user group errors.
+var (
+	ErrInvalidID = errors.New("ID is not in its proper form")
+)
+
+// Handlers manages the set of user endpoints.
+type Handlers struct {
+	user *user.Core
+}
+
+// New constructs a handlers for route access.
+func New(user *user.Core) *Handlers {
+	return &Handlers{
+		user: user,
+	}
+}
+
+// executeUnderTransaction constructs a new Handlers value with the core
+// apis using the transaction of the request, when there is one, so the
+// session variables set on it are visible to the queries.
+func (h *Handlers) executeUnderTransaction(ctx context.Context) (*Handlers, error) {
+	if tx, ok := transaction.Get(ctx); ok {
+		user, err := h.user.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		h = &Handlers{
+			user: user,
+		}
+	}
+
+	return h, nil
+}
+
+// Create adds a new user to the system.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	h, err := h.executeUnderTransaction(ctx)
+	if err != nil {
+		return err
+	}
+
+	var app AppNewUser
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	usr, err := h.user.CreateUser(ctx, toCoreNewUser(app))
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrUniqueEmail):
+			return response.NewError(err, http.StatusConflict)
+		case validate.IsFieldErrors(err):
+			return err
+		default:
+			return fmt.Errorf("create: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusCreated)
+}
+
+// Query returns a list of users with paging.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	h, err := h.executeUnderTransaction(ctx)
+	if err != nil {
+		return err
+	}
+
+	page, err := page.Parse(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.user.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	total, err := h.user.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, response.NewPageDocument(toAppUsers(users), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// QueryByID returns a user by its ID. Admins can query any user, the rest
+// only themselves.
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	h, err := h.executeUnderTransaction(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return response.NewError(ErrInvalidID, http.StatusBadRequest)
+	}
+
+	admin := slices.Contains(web.GetValues(ctx).Roles, user.RoleAdmin)
+
+	usr, err := h.user.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			// Only admins can tell a missing user from someone else's.
+			if !admin {
+				return response.NewError(auth.ErrForbidden, http.StatusForbidden)
+			}
+			return response.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querybyid: id[%s]: %w", id, err)
+		}
+	}
+
+	if !admin && !isSubject(ctx, usr) {
+		return response.NewError(auth.ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// isSubject checks if the user is the one the request was authenticated
+// for, comparing the RUT of the token in its canonical format.
+func isSubject(ctx context.Context, usr user.User) bool {
+	subject, err := rut.Normalize(web.GetValues(ctx).RUT)
+	if err != nil {
+		return false
+	}
+
+	return subject == usr.RUT
+}
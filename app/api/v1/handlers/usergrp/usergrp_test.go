@@ -0,0 +1,78 @@
+package usergrp_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/app/api/v1/handlers/usergrp"
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// asUser sets the RUT and roles of the authenticated user, like
+// mid.Authenticate does with the claims of the token.
+func asUser(rut string, roles ...string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			web.SetRut(ctx, rut)
+			web.SetRoles(ctx, roles)
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func TestQueryByIDAuthorization(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+	core := user.NewCoreWithStore(log, user.NewMemStore(), user.WithBcryptCost(bcrypt.MinCost))
+
+	usr, err := core.CreateUser(context.Background(), user.NewUser{
+		Name:     "Ana Rojas",
+		Email:    "ana@example.com",
+		RUT:      "12.345.678-5",
+		Roles:    []string{user.RoleUser},
+		Password: "gophers123",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	hdl := usergrp.New(core)
+
+	tests := []struct {
+		name     string
+		id       uuid.UUID
+		caller   web.Middleware
+		expected int
+	}{
+		{name: "subject", id: usr.ID, caller: asUser("12345678-5", user.RoleUser), expected: http.StatusOK},
+		{name: "admin", id: usr.ID, caller: asUser("11.111.111-1", user.RoleAdmin), expected: http.StatusOK},
+		{name: "other user", id: usr.ID, caller: asUser("11.111.111-1", user.RoleUser), expected: http.StatusForbidden},
+		{name: "other user missing id", id: uuid.New(), caller: asUser("11.111.111-1", user.RoleUser), expected: http.StatusForbidden},
+		{name: "admin missing id", id: uuid.New(), caller: asUser("11.111.111-1", user.RoleAdmin), expected: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := web.NewApp(nil, mid.Errors(log, nil))
+			app.Handle(http.MethodGet, "v1", "/users/{user_id}", hdl.QueryByID, tt.caller)
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/users/"+tt.id.String(), nil))
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d: %s", tt.expected, w.Code, w.Body)
+			}
+		})
+	}
+}
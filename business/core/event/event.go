@@ -0,0 +1,24 @@
+// Package event provides the support for the cores to publish domain events
+// that downstream integrations can react to.
+package event
+
+import "context"
+
+// Event represents something that happened in a core.
+type Event interface {
+	Name() string
+}
+
+// Bus represents the behavior required to publish events.
+type Bus interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// NopBus is a Bus that discards the events. It's the default for the cores
+// so callers aren't forced to wire a bus.
+type NopBus struct{}
+
+// Publish discards the event.
+func (NopBus) Publish(ctx context.Context, e Event) error {
+	return nil
+}
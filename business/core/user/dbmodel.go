@@ -0,0 +1,77 @@
+package user
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx/dbarray"
+	"github.com/google/uuid"
+)
+
+// dbUser represent the structure we need for moving data
+// between the app and the database.
+type dbUser struct {
+	ID           uuid.UUID      `db:"user_id"`
+	Name         string         `db:"name"`
+	Email        string         `db:"email"`
+	RUT          string         `db:"rut"`
+	Roles        dbarray.String `db:"roles"`
+	PasswordHash string         `db:"password_hash"`
+	Department   sql.NullString `db:"department"`
+	Enabled      bool           `db:"enabled"`
+	DateCreated  time.Time      `db:"date_created"`
+	DateUpdated  time.Time      `db:"date_updated"`
+	DateDeleted  sql.NullTime   `db:"deleted_at"`
+}
+
+func toDBUser(usr User) dbUser {
+	return dbUser{
+		ID:           usr.ID,
+		Name:         usr.Name,
+		Email:        usr.Email,
+		RUT:          usr.RUT,
+		Roles:        usr.Roles,
+		PasswordHash: string(usr.PasswordHash),
+		Department: sql.NullString{
+			String: usr.Department,
+			Valid:  usr.Department != "",
+		},
+		Enabled:     usr.Enabled,
+		DateCreated: usr.DateCreated.UTC(),
+		DateUpdated: usr.DateUpdated.UTC(),
+		DateDeleted: sql.NullTime{
+			Time:  usr.DateDeleted.UTC(),
+			Valid: !usr.DateDeleted.IsZero(),
+		},
+	}
+}
+
+func toCoreUser(dbUsr dbUser) User {
+	usr := User{
+		ID:           dbUsr.ID,
+		Name:         dbUsr.Name,
+		Email:        dbUsr.Email,
+		RUT:          dbUsr.RUT,
+		Roles:        dbUsr.Roles,
+		PasswordHash: []byte(dbUsr.PasswordHash),
+		Department:   dbUsr.Department.String,
+		Enabled:      dbUsr.Enabled,
+		DateCreated:  dbUsr.DateCreated.In(time.Local),
+		DateUpdated:  dbUsr.DateUpdated.In(time.Local),
+	}
+
+	if dbUsr.DateDeleted.Valid {
+		usr.DateDeleted = dbUsr.DateDeleted.Time.In(time.Local)
+	}
+
+	return usr
+}
+
+func toCoreUserSlice(dbUsers []dbUser) []User {
+	usrs := make([]User, len(dbUsers))
+	for i, dbUsr := range dbUsers {
+		usrs[i] = toCoreUser(dbUsr)
+	}
+
+	return usrs
+}
@@ -0,0 +1,364 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx/dbarray"
+	"github.com/Yeremi528/laboratorio/business/data/order"
+	"github.com/Yeremi528/laboratorio/business/data/transaction"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// emailUniqueIndex is the unique index that keeps the emails of the users
+// that aren't deleted unique.
+const emailUniqueIndex = "users_email_active_key"
+
+// DBStore manages the set of APIs for user database access.
+type DBStore struct {
+	db     sqlx.ExtContext
+	reader func() sqlx.ExtContext
+}
+
+// NewDBStore constructs the api for data access.
+func NewDBStore(db *sqlx.DB) *DBStore {
+	return &DBStore{
+		db:     db,
+		reader: func() sqlx.ExtContext { return db },
+	}
+}
+
+// NewDBStoreWithReplicas constructs the api for data access that sends the
+// queries to the read replicas of db and the writes to its primary.
+func NewDBStoreWithReplicas(db *pgx.DB) *DBStore {
+	return &DBStore{
+		db:     db.Writer(),
+		reader: db.Reader,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new DBStore value that will use the
+// specified transaction in any store related calls.
+func (s *DBStore) ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error) {
+	ec, ok := tx.(sqlx.ExtContext)
+	if !ok {
+		return nil, fmt.Errorf("transaction not of type sqlx.ExtContext: %T", tx)
+	}
+
+	store := DBStore{
+		db:     ec,
+		reader: func() sqlx.ExtContext { return ec },
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new user into the database.
+func (s *DBStore) Create(ctx context.Context, usr User) error {
+	const q = `
+	INSERT INTO users
+		(user_id, name, email, rut, roles, password_hash, department, enabled, date_created, date_updated)
+	VALUES
+		(:user_id, :name, :email, :rut, :roles, :password_hash, :department, :enabled, :date_created, :date_updated)`
+
+	if err := pgx.RunCUD(ctx, s.db, q, toDBUser(usr)); err != nil {
+		if pgx.IsDuplicatedEntry(err, emailUniqueIndex) {
+			return fmt.Errorf("namedexeccontext: %w", ErrUniqueEmail)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update sets the columns of the fields provided in uu, leaving the rest of
+// the row untouched, so concurrent updates of different fields don't
+// overwrite each other. The RUT in uu must be canonical and the password is
+// stored from passwordHash, uu.Password is ignored.
+func (s *DBStore) Update(ctx context.Context, userID uuid.UUID, uu UpdateUser, passwordHash []byte, dateUpdated time.Time) error {
+	data := map[string]any{
+		"user_id": userID.String(),
+	}
+
+	var set []string
+	add := func(column string, value any) {
+		data[column] = value
+		set = append(set, column+" = :"+column)
+	}
+
+	if uu.Name != nil {
+		add("name", *uu.Name)
+	}
+
+	if uu.Email != nil {
+		add("email", *uu.Email)
+	}
+
+	if uu.RUT != nil {
+		add("rut", *uu.RUT)
+	}
+
+	if uu.Roles != nil {
+		add("roles", dbarray.String(uu.Roles))
+	}
+
+	if uu.Department != nil {
+		add("department", sql.NullString{String: *uu.Department, Valid: *uu.Department != ""})
+	}
+
+	if passwordHash != nil {
+		add("password_hash", string(passwordHash))
+	}
+
+	if uu.Enabled != nil {
+		add("enabled", *uu.Enabled)
+	}
+
+	add("date_updated", dateUpdated.UTC())
+
+	q := `
+	UPDATE
+		users
+	SET
+		` + strings.Join(set, ", ") + `
+	WHERE
+		user_id = :user_id AND deleted_at IS NULL`
+
+	if err := pgx.RunCUD(ctx, s.db, q, data); err != nil {
+		if pgx.IsDuplicatedEntry(err, emailUniqueIndex) {
+			return fmt.Errorf("namedexeccontext: %w", ErrUniqueEmail)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes the user by setting its deleted_at column.
+func (s *DBStore) Delete(ctx context.Context, userID uuid.UUID, deletedAt time.Time) error {
+	data := struct {
+		ID        string    `db:"user_id"`
+		DeletedAt time.Time `db:"deleted_at"`
+	}{
+		ID:        userID.String(),
+		DeletedAt: deletedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		users
+	SET
+		deleted_at = :deleted_at
+	WHERE
+		user_id = :user_id AND deleted_at IS NULL`
+
+	if err := pgx.RunCUDAffected(ctx, s.db, q, data); err != nil {
+		if errors.Is(err, pgx.ErrDBNotFound) {
+			return fmt.Errorf("namedexeccontext: %w", ErrNotFound)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing users from the database.
+func (s *DBStore) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	return s.query(ctx, filter, orderBy, pageNumber, rowsPerPage, false)
+}
+
+// QueryIncludingDeleted retrieves a list of users from the database,
+// including the deleted ones.
+func (s *DBStore) QueryIncludingDeleted(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	return s.query(ctx, filter, orderBy, pageNumber, rowsPerPage, true)
+}
+
+func (s *DBStore) query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int, includeDeleted bool) ([]User, error) {
+	data := map[string]any{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, rut, roles, password_hash, department, enabled, date_created, date_updated, deleted_at
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	applyFilter(filter, data, buf, includeDeleted)
+
+	orderByClause, err := pgx.OrderBy(orderBy.Field+":"+orderBy.Direction, orderByFields)
+	if err != nil {
+		return nil, fmt.Errorf("orderby: %w", err)
+	}
+
+	buf.WriteString(" " + orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbUsrs []dbUser
+	if err := pgx.RunNamedQuerySlice(ctx, s.reader(), buf.String(), data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreUserSlice(dbUsrs), nil
+}
+
+// Search retrieves a page of the users whose name or email contain term. The
+// term is bound as a parameter with its LIKE wildcards escaped, so it's
+// matched literally.
+func (s *DBStore) Search(ctx context.Context, term string, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	data := map[string]any{
+		"term":          "%" + escapeLike(term) + "%",
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, rut, roles, password_hash, department, enabled, date_created, date_updated, deleted_at
+	FROM
+		users
+	WHERE
+		deleted_at IS NULL AND (name ILIKE :term OR email ILIKE :term)`
+
+	buf := bytes.NewBufferString(q)
+
+	orderByClause, err := pgx.OrderBy(orderBy.Field+":"+orderBy.Direction, orderByFields)
+	if err != nil {
+		return nil, fmt.Errorf("orderby: %w", err)
+	}
+
+	buf.WriteString(" " + orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbUsrs []dbUser
+	if err := pgx.RunNamedQuerySlice(ctx, s.reader(), buf.String(), data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreUserSlice(dbUsrs), nil
+}
+
+// QueryByCursor retrieves up to limit users ordered by id with an id greater
+// than afterID, using the primary key index instead of an offset.
+func (s *DBStore) QueryByCursor(ctx context.Context, afterID uuid.UUID, limit int) ([]User, error) {
+	data := struct {
+		AfterID string `db:"after_id"`
+		Limit   int    `db:"limit"`
+	}{
+		AfterID: afterID.String(),
+		Limit:   limit,
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, rut, roles, password_hash, department, enabled, date_created, date_updated, deleted_at
+	FROM
+		users
+	WHERE
+		user_id > :after_id AND deleted_at IS NULL
+	ORDER BY
+		user_id
+	LIMIT :limit`
+
+	var dbUsrs []dbUser
+	if err := pgx.RunNamedQuerySlice(ctx, s.reader(), q, data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreUserSlice(dbUsrs), nil
+}
+
+// Count returns the total number of users in the DB.
+func (s *DBStore) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1) AS count
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	applyFilter(filter, data, buf, false)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := pgx.RunNamedQuery(ctx, s.reader(), buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquery: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified user from the database. The primary is used
+// since the user is read right after being updated.
+func (s *DBStore) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, rut, roles, password_hash, department, enabled, date_created, date_updated, deleted_at
+	FROM
+		users
+	WHERE
+		user_id = :user_id AND deleted_at IS NULL`
+
+	var dbUsr dbUser
+	if err := pgx.RunNamedQuery(ctx, s.db, q, data, &dbUsr); err != nil {
+		if errors.Is(err, pgx.ErrDBNotFound) {
+			return User{}, fmt.Errorf("namedquery: %w", ErrNotFound)
+		}
+		return User{}, fmt.Errorf("namedquery: %w", err)
+	}
+
+	return toCoreUser(dbUsr), nil
+}
+
+// QueryByEmail gets the specified user from the database by email.
+func (s *DBStore) QueryByEmail(ctx context.Context, email string) (User, error) {
+	data := struct {
+		Email string `db:"email"`
+	}{
+		Email: email,
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, rut, roles, password_hash, department, enabled, date_created, date_updated, deleted_at
+	FROM
+		users
+	WHERE
+		email = :email AND deleted_at IS NULL`
+
+	var dbUsr dbUser
+	if err := pgx.RunNamedQuery(ctx, s.reader(), q, data, &dbUsr); err != nil {
+		if errors.Is(err, pgx.ErrDBNotFound) {
+			return User{}, fmt.Errorf("namedquery: %w", ErrNotFound)
+		}
+		return User{}, fmt.Errorf("namedquery: %w", err)
+	}
+
+	return toCoreUser(dbUsr), nil
+}
+
+// likeEscaper escapes the LIKE wildcards using the default escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes the LIKE wildcards in value match literally.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of event names published by the user core.
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
+
+// UserCreated is published when a user is created.
+type UserCreated struct {
+	UserID    uuid.UUID
+	Timestamp time.Time
+}
+
+// Name implements the event.Event interface.
+func (UserCreated) Name() string { return EventUserCreated }
+
+// UserUpdated is published when a user is updated.
+type UserUpdated struct {
+	UserID    uuid.UUID
+	Timestamp time.Time
+}
+
+// Name implements the event.Event interface.
+func (UserUpdated) Name() string { return EventUserUpdated }
+
+// UserDeleted is published when a user is deleted.
+type UserDeleted struct {
+	UserID    uuid.UUID
+	Timestamp time.Time
+}
+
+// Name implements the event.Event interface.
+func (UserDeleted) Name() string { return EventUserDeleted }
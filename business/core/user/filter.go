@@ -0,0 +1,58 @@
+package user
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Yeremi528/laboratorio/foundation/rut"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on. Fields
+// that are nil are not used to filter.
+type QueryFilter struct {
+	ID    *uuid.UUID
+	Name  *string
+	Email *string
+	RUT   *string
+}
+
+// applyFilter adds the WHERE clause for the filter to the query, and the
+// values it references to data. The deleted users are left out unless
+// includeDeleted is set.
+func applyFilter(filter QueryFilter, data map[string]any, buf *bytes.Buffer, includeDeleted bool) {
+	var wc []string
+
+	if !includeDeleted {
+		wc = append(wc, "deleted_at IS NULL")
+	}
+
+	if filter.ID != nil {
+		data["user_id"] = *filter.ID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Name != nil {
+		data["name"] = "%" + *filter.Name + "%"
+		wc = append(wc, "name ILIKE :name")
+	}
+
+	if filter.Email != nil {
+		data["email"] = *filter.Email
+		wc = append(wc, "email = :email")
+	}
+
+	if filter.RUT != nil {
+		value := *filter.RUT
+		if canonical, err := rut.Normalize(value); err == nil {
+			value = canonical
+		}
+		data["rut"] = value
+		wc = append(wc, "rut = :rut")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,317 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/data/order"
+	"github.com/Yeremi528/laboratorio/business/data/transaction"
+	"github.com/Yeremi528/laboratorio/foundation/rut"
+	"github.com/google/uuid"
+)
+
+// MemStore is an in-memory Storer meant for tests, so the core can be
+// exercised without Postgres. It follows the database semantics: emails are
+// unique among the users that aren't deleted and deleted users are kept but
+// left out of the queries.
+type MemStore struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]User
+}
+
+// NewMemStore constructs an empty in-memory store.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		users: make(map[uuid.UUID]User),
+	}
+}
+
+// ExecuteUnderTransaction returns the same store, the in-memory store has no
+// transactions.
+func (s *MemStore) ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error) {
+	return s, nil
+}
+
+// Create adds a new user to the store.
+func (s *MemStore) Create(ctx context.Context, usr User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.emailTaken(usr.ID, usr.Email) {
+		return fmt.Errorf("create: %w", ErrUniqueEmail)
+	}
+
+	s.users[usr.ID] = usr
+
+	return nil
+}
+
+// Update changes the fields of the user provided in uu. Deleted users are
+// left unchanged.
+func (s *MemStore) Update(ctx context.Context, userID uuid.UUID, uu UpdateUser, passwordHash []byte, dateUpdated time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usr, exists := s.users[userID]
+	if !exists || !usr.DateDeleted.IsZero() {
+		return nil
+	}
+
+	if uu.Email != nil && s.emailTaken(userID, *uu.Email) {
+		return fmt.Errorf("update: %w", ErrUniqueEmail)
+	}
+
+	if uu.Name != nil {
+		usr.Name = *uu.Name
+	}
+
+	if uu.Email != nil {
+		usr.Email = *uu.Email
+	}
+
+	if uu.RUT != nil {
+		usr.RUT = *uu.RUT
+	}
+
+	if uu.Roles != nil {
+		usr.Roles = uu.Roles
+	}
+
+	if uu.Department != nil {
+		usr.Department = *uu.Department
+	}
+
+	if passwordHash != nil {
+		usr.PasswordHash = passwordHash
+	}
+
+	if uu.Enabled != nil {
+		usr.Enabled = *uu.Enabled
+	}
+
+	usr.DateUpdated = dateUpdated
+	s.users[userID] = usr
+
+	return nil
+}
+
+// Delete soft deletes the user by setting its DateDeleted field.
+func (s *MemStore) Delete(ctx context.Context, userID uuid.UUID, deletedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usr, exists := s.users[userID]
+	if !exists || !usr.DateDeleted.IsZero() {
+		return fmt.Errorf("delete: %w", ErrNotFound)
+	}
+
+	usr.DateDeleted = deletedAt.UTC()
+	s.users[userID] = usr
+
+	return nil
+}
+
+// Query retrieves a page of the users matching the filter.
+func (s *MemStore) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	return s.query(filter, orderBy, pageNumber, rowsPerPage, false)
+}
+
+// QueryIncludingDeleted retrieves a page of the users matching the filter,
+// including the deleted ones.
+func (s *MemStore) QueryIncludingDeleted(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	return s.query(filter, orderBy, pageNumber, rowsPerPage, true)
+}
+
+func (s *MemStore) query(filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int, includeDeleted bool) ([]User, error) {
+	less, err := memLess(orderBy)
+	if err != nil {
+		return nil, fmt.Errorf("orderby: %w", err)
+	}
+
+	s.mu.RLock()
+	usrs := s.filter(filter, includeDeleted)
+	s.mu.RUnlock()
+
+	sort.SliceStable(usrs, func(i, j int) bool {
+		return less(usrs[i], usrs[j])
+	})
+
+	return paginate(usrs, pageNumber, rowsPerPage), nil
+}
+
+// Search retrieves a page of the users whose name or email contain term,
+// ignoring the case.
+func (s *MemStore) Search(ctx context.Context, term string, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	less, err := memLess(orderBy)
+	if err != nil {
+		return nil, fmt.Errorf("orderby: %w", err)
+	}
+
+	term = strings.ToLower(term)
+
+	s.mu.RLock()
+	usrs := []User{}
+	for _, usr := range s.filter(QueryFilter{}, false) {
+		if strings.Contains(strings.ToLower(usr.Name), term) || strings.Contains(strings.ToLower(usr.Email), term) {
+			usrs = append(usrs, usr)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(usrs, func(i, j int) bool {
+		return less(usrs[i], usrs[j])
+	})
+
+	return paginate(usrs, pageNumber, rowsPerPage), nil
+}
+
+// QueryByCursor retrieves up to limit users ordered by id with an id greater
+// than afterID.
+func (s *MemStore) QueryByCursor(ctx context.Context, afterID uuid.UUID, limit int) ([]User, error) {
+	s.mu.RLock()
+	usrs := s.filter(QueryFilter{}, false)
+	s.mu.RUnlock()
+
+	// The string form of the ids sorts like the uuid type in Postgres.
+	after := afterID.String()
+
+	page := []User{}
+	for _, usr := range usrs {
+		if usr.ID.String() > after {
+			page = append(page, usr)
+		}
+	}
+
+	sort.Slice(page, func(i, j int) bool {
+		return page[i].ID.String() < page[j].ID.String()
+	})
+
+	if len(page) > limit {
+		page = page[:limit]
+	}
+
+	return page, nil
+}
+
+// Count returns the number of users matching the filter.
+func (s *MemStore) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.filter(filter, false)), nil
+}
+
+// QueryByID gets the specified user from the store.
+func (s *MemStore) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usr, exists := s.users[userID]
+	if !exists || !usr.DateDeleted.IsZero() {
+		return User{}, fmt.Errorf("query: %w", ErrNotFound)
+	}
+
+	return usr, nil
+}
+
+// QueryByEmail gets the specified user from the store by email.
+func (s *MemStore) QueryByEmail(ctx context.Context, email string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, usr := range s.users {
+		if usr.Email == email && usr.DateDeleted.IsZero() {
+			return usr, nil
+		}
+	}
+
+	return User{}, fmt.Errorf("query: %w", ErrNotFound)
+}
+
+// emailTaken reports whether another user already uses the email. Deleted
+// users release their email, like the partial unique index in the database.
+func (s *MemStore) emailTaken(userID uuid.UUID, email string) bool {
+	for _, usr := range s.users {
+		if usr.ID != userID && usr.Email == email && usr.DateDeleted.IsZero() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter mirrors applyFilter for the users in the store.
+func (s *MemStore) filter(filter QueryFilter, includeDeleted bool) []User {
+	var canonicalRUT string
+	if filter.RUT != nil {
+		canonicalRUT = *filter.RUT
+		if canonical, err := rut.Normalize(canonicalRUT); err == nil {
+			canonicalRUT = canonical
+		}
+	}
+
+	usrs := []User{}
+	for _, usr := range s.users {
+		switch {
+		case !includeDeleted && !usr.DateDeleted.IsZero():
+			continue
+		case filter.ID != nil && usr.ID != *filter.ID:
+			continue
+		case filter.Name != nil && !strings.Contains(strings.ToLower(usr.Name), strings.ToLower(*filter.Name)):
+			continue
+		case filter.Email != nil && usr.Email != *filter.Email:
+			continue
+		case filter.RUT != nil && usr.RUT != canonicalRUT:
+			continue
+		}
+
+		usrs = append(usrs, usr)
+	}
+
+	return usrs
+}
+
+// memLess returns the comparison used to sort the users for orderBy.
+func memLess(orderBy order.By) (func(a, b User) bool, error) {
+	var less func(a, b User) bool
+
+	switch orderBy.Field {
+	case OrderByID:
+		less = func(a, b User) bool { return a.ID.String() < b.ID.String() }
+	case OrderByName:
+		less = func(a, b User) bool { return a.Name < b.Name }
+	case OrderByEmail:
+		less = func(a, b User) bool { return a.Email < b.Email }
+	case OrderByRoles:
+		less = func(a, b User) bool { return strings.Join(a.Roles, ",") < strings.Join(b.Roles, ",") }
+	case OrderByEnabled:
+		less = func(a, b User) bool { return !a.Enabled && b.Enabled }
+	default:
+		return nil, fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	if orderBy.Direction == order.DESC {
+		return func(a, b User) bool { return less(b, a) }, nil
+	}
+
+	return less, nil
+}
+
+// paginate returns the users in the requested page.
+func paginate(usrs []User, pageNumber int, rowsPerPage int) []User {
+	offset := (pageNumber - 1) * rowsPerPage
+	if offset < 0 || offset >= len(usrs) {
+		return []User{}
+	}
+
+	end := offset + rowsPerPage
+	if end > len(usrs) {
+		end = len(usrs)
+	}
+
+	return usrs[offset:end]
+}
@@ -0,0 +1,123 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/business/data/order"
+)
+
+func TestMemStore(t *testing.T) {
+	core := newMemCore(t)
+	ctx := context.Background()
+
+	var ids []string
+	for _, nu := range []user.NewUser{
+		{Name: "Bruno Díaz", Email: "bruno@example.com", RUT: "11.111.111-1", Password: "gophers123"},
+		{Name: "Ana Rojas", Email: "ana@example.com", RUT: "12.345.678-5", Password: "gophers123"},
+		{Name: "Carla Soto", Email: "carla@example.com", RUT: "22.222.222-2", Password: "gophers123"},
+	} {
+		usr, err := core.CreateUser(ctx, nu)
+		if err != nil {
+			t.Fatalf("unexpected error creating %s: %s", nu.Email, err)
+		}
+		ids = append(ids, usr.ID.String())
+	}
+
+	t.Run("duplicated email", func(t *testing.T) {
+		nu := validNewUser()
+		nu.Email = "ana@example.com"
+
+		if _, err := core.CreateUser(ctx, nu); !errors.Is(err, user.ErrUniqueEmail) {
+			t.Fatalf("expected ErrUniqueEmail, got %v", err)
+		}
+	})
+
+	t.Run("order and page", func(t *testing.T) {
+		usrs, err := core.Query(ctx, user.QueryFilter{}, order.NewBy(user.OrderByName, order.DESC), 1, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(usrs) != 2 || usrs[0].Name != "Carla Soto" || usrs[1].Name != "Bruno Díaz" {
+			t.Fatalf("unexpected first page %+v", usrs)
+		}
+
+		usrs, err = core.Query(ctx, user.QueryFilter{}, order.NewBy(user.OrderByName, order.DESC), 2, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(usrs) != 1 || usrs[0].Name != "Ana Rojas" {
+			t.Fatalf("unexpected second page %+v", usrs)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		name := "rojas"
+		rut := "123456785"
+
+		usrs, err := core.Query(ctx, user.QueryFilter{Name: &name, RUT: &rut}, user.DefaultOrderBy, 1, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(usrs) != 1 || usrs[0].Email != "ana@example.com" {
+			t.Fatalf("expected ana, got %+v", usrs)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		usr, err := core.Authenticate(ctx, "bruno@example.com", "gophers123")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := core.Delete(ctx, usr.ID); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if _, err := core.QueryByID(ctx, usr.ID); !errors.Is(err, user.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+
+		count, err := core.Count(ctx, user.QueryFilter{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if count != len(ids)-1 {
+			t.Fatalf("expected %d users, got %d", len(ids)-1, count)
+		}
+
+		usrs, err := core.QueryIncludingDeleted(ctx, user.QueryFilter{ID: &usr.ID}, user.DefaultOrderBy, 1, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(usrs) != 1 || usrs[0].DateDeleted.IsZero() {
+			t.Fatalf("expected the deleted user through the admin path, got %+v", usrs)
+		}
+	})
+
+	t.Run("create after delete", func(t *testing.T) {
+		nu := validNewUser()
+		nu.Email = "bruno@example.com"
+
+		usr, err := core.CreateUser(ctx, nu)
+		if err != nil {
+			t.Fatalf("expected the email of the deleted user to be free, got %v", err)
+		}
+
+		got, err := core.Authenticate(ctx, "bruno@example.com", nu.Password)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if got.ID != usr.ID {
+			t.Fatalf("expected the new user, got %+v", got)
+		}
+	})
+}
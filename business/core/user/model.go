@@ -0,0 +1,160 @@
+package user
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/rut"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/google/uuid"
+)
+
+// Set of roles a user can have.
+const (
+	RoleAdmin = "ADMIN"
+	RoleUser  = "USER"
+)
+
+// User represents information about an individual user.
+type User struct {
+	ID           uuid.UUID
+	Name         string
+	Email        string
+	RUT          string
+	Roles        []string
+	PasswordHash []byte
+	Department   string
+	Enabled      bool
+	DateCreated  time.Time
+	DateUpdated  time.Time
+	DateDeleted  time.Time
+}
+
+// NewUser contains information needed to create a new user.
+type NewUser struct {
+	Name       string
+	Email      string
+	RUT        string
+	Roles      []string
+	Department string
+	Password   string
+}
+
+// Validate checks the data in the model is considered clean.
+func (nu NewUser) Validate() error {
+	var fields validate.FieldErrors
+
+	fields = checkName(fields, nu.Name)
+	fields = checkEmail(fields, nu.Email)
+	fields = checkRUT(fields, nu.RUT)
+	fields = checkPassword(fields, nu.Password)
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	return nil
+}
+
+// UpdateUser contains information needed to update a user. Fields that are
+// nil are left unchanged.
+type UpdateUser struct {
+	Name       *string
+	Email      *string
+	RUT        *string
+	Roles      []string
+	Department *string
+	Password   *string
+	Enabled    *bool
+}
+
+// Validate checks the fields provided in the model are considered clean.
+func (uu UpdateUser) Validate() error {
+	var fields validate.FieldErrors
+
+	if uu.Name != nil {
+		fields = checkName(fields, *uu.Name)
+	}
+
+	if uu.Email != nil {
+		fields = checkEmail(fields, *uu.Email)
+	}
+
+	if uu.RUT != nil {
+		fields = checkRUT(fields, *uu.RUT)
+	}
+
+	if uu.Password != nil {
+		fields = checkPassword(fields, *uu.Password)
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	return nil
+}
+
+// empty reports whether the update doesn't change any field.
+func (uu UpdateUser) empty() bool {
+	return uu.Name == nil && uu.Email == nil && uu.RUT == nil && uu.Roles == nil &&
+		uu.Department == nil && uu.Password == nil && uu.Enabled == nil
+}
+
+// =============================================================================
+
+func checkName(fields validate.FieldErrors, name string) validate.FieldErrors {
+	if strings.TrimSpace(name) == "" {
+		fields = append(fields, validate.FieldError{Field: "name", Err: "name is required"})
+	}
+
+	return fields
+}
+
+// ValidateEmail checks the value is a plain email address, like
+// jane@example.com, without a display name.
+func ValidateEmail(email string) error {
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return errors.New("email is not valid")
+	}
+
+	return nil
+}
+
+// ValidateRUT checks the value is a Chilean RUT, dotted or plain, whose
+// verifier digit matches the mod 11 algorithm.
+func ValidateRUT(value string) error {
+	return rut.Validate(value)
+}
+
+func checkEmail(fields validate.FieldErrors, email string) validate.FieldErrors {
+	if err := ValidateEmail(email); err != nil {
+		fields = append(fields, validate.FieldError{Field: "email", Err: err.Error()})
+	}
+
+	return fields
+}
+
+func checkRUT(fields validate.FieldErrors, value string) validate.FieldErrors {
+	if err := ValidateRUT(value); err != nil {
+		fields = append(fields, validate.FieldError{Field: "rut", Err: err.Error()})
+	}
+
+	return fields
+}
+
+// maxPasswordBytes is the longest password bcrypt can hash.
+const maxPasswordBytes = 72
+
+func checkPassword(fields validate.FieldErrors, password string) validate.FieldErrors {
+	switch {
+	case password == "":
+		fields = append(fields, validate.FieldError{Field: "password", Err: "password is required"})
+	case len(password) > maxPasswordBytes:
+		fields = append(fields, validate.FieldError{Field: "password", Err: "password must be at most 72 bytes"})
+	}
+
+	return fields
+}
@@ -0,0 +1,113 @@
+package user_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+)
+
+func validNewUser() user.NewUser {
+	return user.NewUser{
+		Name:     "Ana Rojas",
+		Email:    "ana.rojas@example.com",
+		RUT:      "12.345.678-5",
+		Roles:    []string{"USER"},
+		Password: "gophers123",
+	}
+}
+
+func TestNewUserValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(nu *user.NewUser)
+		field  string
+	}{
+		{name: "valid"},
+		{name: "empty name", modify: func(nu *user.NewUser) { nu.Name = "  " }, field: "name"},
+		{name: "invalid email", modify: func(nu *user.NewUser) { nu.Email = "ana.rojas" }, field: "email"},
+		{name: "email with display name", modify: func(nu *user.NewUser) { nu.Email = "Ana <ana@example.com>" }, field: "email"},
+		{name: "invalid rut", modify: func(nu *user.NewUser) { nu.RUT = "12.345.678-9" }, field: "rut"},
+		{name: "empty password", modify: func(nu *user.NewUser) { nu.Password = "" }, field: "password"},
+		{name: "long password", modify: func(nu *user.NewUser) { nu.Password = strings.Repeat("a", 73) }, field: "password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nu := validNewUser()
+			if tt.modify != nil {
+				tt.modify(&nu)
+			}
+
+			assertFieldError(t, nu.Validate(), tt.field)
+		})
+	}
+}
+
+func TestUpdateUserValidate(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name  string
+		uu    user.UpdateUser
+		field string
+	}{
+		{name: "empty update", uu: user.UpdateUser{}},
+		{name: "valid", uu: user.UpdateUser{Name: ptr("Ana"), Email: ptr("ana@example.com"), RUT: ptr("12345678-5")}},
+		{name: "empty name", uu: user.UpdateUser{Name: ptr("")}, field: "name"},
+		{name: "invalid email", uu: user.UpdateUser{Email: ptr("@example.com")}, field: "email"},
+		{name: "invalid rut", uu: user.UpdateUser{RUT: ptr("1-1")}, field: "rut"},
+		{name: "empty password", uu: user.UpdateUser{Password: ptr("")}, field: "password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFieldError(t, tt.uu.Validate(), tt.field)
+		})
+	}
+}
+
+// assertFieldError checks the error only reports the field, or that there is
+// no error when the field is empty.
+func assertFieldError(t *testing.T, err error, field string) {
+	t.Helper()
+
+	if field == "" {
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		return
+	}
+
+	fields := validate.GetFieldErrors(err)
+	if len(fields) != 1 || fields[0].Field != field {
+		t.Fatalf("expected a single error on %s, got %v", field, err)
+	}
+}
+
+func TestValidateEmailAndRUT(t *testing.T) {
+	for _, email := range []string{"ana@example.com", "ana.rojas+lab@sub.example.cl"} {
+		if err := user.ValidateEmail(email); err != nil {
+			t.Fatalf("expected %s to be valid, got %s", email, err)
+		}
+	}
+
+	for _, email := range []string{"", "ana", "ana@", "Ana <ana@example.com>", " ana@example.com"} {
+		if err := user.ValidateEmail(email); err == nil {
+			t.Fatalf("expected %q to be invalid", email)
+		}
+	}
+
+	for _, value := range []string{"12.345.678-5", "12345678-5", "7.775.735-K"} {
+		if err := user.ValidateRUT(value); err != nil {
+			t.Fatalf("expected %s to be valid, got %s", value, err)
+		}
+	}
+
+	for _, value := range []string{"12.345.678-9", "1-1", "abc"} {
+		if err := user.ValidateRUT(value); err == nil {
+			t.Fatalf("expected %s to be invalid", value)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package user
+
+import "github.com/Yeremi528/laboratorio/business/data/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByID, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID      = "user_id"
+	OrderByName    = "name"
+	OrderByEmail   = "email"
+	OrderByRoles   = "roles"
+	OrderByEnabled = "enabled"
+)
+
+// orderByFields maps the fields to the columns they are ordered by.
+var orderByFields = map[string]string{
+	OrderByID:      "user_id",
+	OrderByName:    "name",
+	OrderByEmail:   "email",
+	OrderByRoles:   "roles",
+	OrderByEnabled: "enabled",
+}
@@ -1,24 +1,367 @@
+// Package user provides the core business API for users.
 package user
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Yeremi528/laboratorio/business/core/event"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/Yeremi528/laboratorio/business/data/order"
+	"github.com/Yeremi528/laboratorio/business/data/transaction"
 	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/rut"
+	"github.com/Yeremi528/laboratorio/foundation/timecl"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound              = errors.New("user not found")
+	ErrUniqueEmail           = errors.New("email is not unique")
+	ErrAuthenticationFailure = errors.New("authentication failed")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data. The deleted users are left out of every query but
+// QueryIncludingDeleted.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, usr User) error
+	Update(ctx context.Context, userID uuid.UUID, uu UpdateUser, passwordHash []byte, dateUpdated time.Time) error
+	Delete(ctx context.Context, userID uuid.UUID, deletedAt time.Time) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error)
+	QueryIncludingDeleted(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error)
+	QueryByCursor(ctx context.Context, afterID uuid.UUID, limit int) ([]User, error)
+	Search(ctx context.Context, term string, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
+	QueryByEmail(ctx context.Context, email string) (User, error)
+}
+
+// Core manages the set of APIs for user access.
 type Core struct {
-	logger *logger.Logger
-	db     *sqlx.DB
+	logger     *logger.Logger
+	storer     Storer
+	bus        event.Bus
+	bcryptCost int
+}
+
+// Option represents a function that configures a Core.
+type Option func(c *Core)
+
+// WithBcryptCost sets the cost used to hash the passwords. The default is
+// bcrypt.DefaultCost, tests can lower it to bcrypt.MinCost to run faster.
+func WithBcryptCost(cost int) Option {
+	return func(c *Core) {
+		c.bcryptCost = cost
+	}
+}
+
+// WithEventBus sets the bus the user events are published to. The default
+// is an event.NopBus.
+func WithEventBus(bus event.Bus) Option {
+	return func(c *Core) {
+		c.bus = bus
+	}
+}
+
+// NewCore constructs a core for user api access backed by the database.
+func NewCore(logger *logger.Logger, db *sqlx.DB, opts ...Option) *Core {
+	return NewCoreWithStore(logger, NewDBStore(db), opts...)
+}
+
+// NewCoreWithReplicas constructs a core for user api access that sends the
+// queries to the read replicas of db and the writes to its primary.
+func NewCoreWithReplicas(logger *logger.Logger, db *pgx.DB, opts ...Option) *Core {
+	return NewCoreWithStore(logger, NewDBStoreWithReplicas(db), opts...)
+}
+
+// NewCoreWithStore constructs a core for user api access backed by the
+// provided storer, like a MemStore in tests.
+func NewCoreWithStore(logger *logger.Logger, storer Storer, opts ...Option) *Core {
+	c := Core{
+		logger:     logger,
+		storer:     storer,
+		bus:        event.NopBus{},
+		bcryptCost: bcrypt.DefaultCost,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	// Generated up front so the first unknown email isn't slower.
+	dummyHash(c.bcryptCost)
+
+	return &c
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	core := Core{
+		logger:     c.logger,
+		storer:     storer,
+		bus:        c.bus,
+		bcryptCost: c.bcryptCost,
+	}
+
+	return &core, nil
 }
 
-func NewCore(logger *logger.Logger, db *sqlx.DB) *Core {
-	return &Core{
-		logger: logger,
-		db:     db,
+// CreateUser validates the new user and adds it to the database.
+func (c *Core) CreateUser(ctx context.Context, nu NewUser) (User, error) {
+	if err := nu.Validate(); err != nil {
+		return User{}, fmt.Errorf("validate: %w", err)
 	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(nu.Password), c.bcryptCost)
+	if err != nil {
+		return User{}, fmt.Errorf("generatefrompassword: %w", err)
+	}
+
+	// The RUT was validated, it's stored in its canonical format so it can
+	// be looked up whatever format it's provided in.
+	canonicalRUT, err := rut.Normalize(nu.RUT)
+	if err != nil {
+		return User{}, fmt.Errorf("normalize: %w", err)
+	}
+
+	now := time.Now()
+
+	usr := User{
+		ID:           uuid.New(),
+		Name:         nu.Name,
+		Email:        nu.Email,
+		RUT:          canonicalRUT,
+		Roles:        nu.Roles,
+		PasswordHash: hash,
+		Department:   nu.Department,
+		Enabled:      true,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	if err := c.storer.Create(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("create: %w", err)
+	}
+
+	c.publish(ctx, UserCreated{UserID: usr.ID, Timestamp: now})
+
+	return usr, nil
+}
+
+// Update changes the fields of the specified user provided in uu, leaving
+// the rest unchanged, and returns the updated user. Only the provided fields
+// are written, so concurrent updates of different fields don't overwrite
+// each other. An update without fields returns the user as is.
+func (c *Core) Update(ctx context.Context, userID uuid.UUID, uu UpdateUser) (User, error) {
+	if err := uu.Validate(); err != nil {
+		return User{}, fmt.Errorf("validate: %w", err)
+	}
+
+	if uu.empty() {
+		return c.QueryByID(ctx, userID)
+	}
+
+	if uu.RUT != nil {
+		canonicalRUT, err := rut.Normalize(*uu.RUT)
+		if err != nil {
+			return User{}, fmt.Errorf("normalize: %w", err)
+		}
+		uu.RUT = &canonicalRUT
+	}
+
+	var passwordHash []byte
+	if uu.Password != nil {
+		hash, err := bcrypt.GenerateFromPassword([]byte(*uu.Password), c.bcryptCost)
+		if err != nil {
+			return User{}, fmt.Errorf("generatefrompassword: %w", err)
+		}
+		passwordHash = hash
+	}
+
+	now := time.Now()
+
+	if err := c.storer.Update(ctx, userID, uu, passwordHash, now); err != nil {
+		return User{}, fmt.Errorf("update: userID[%s]: %w", userID, err)
+	}
+
+	usr, err := c.QueryByID(ctx, userID)
+	if err != nil {
+		return User{}, err
+	}
+
+	c.publish(ctx, UserUpdated{UserID: usr.ID, Timestamp: now})
+
+	return usr, nil
 }
 
-func (c *Core) CreateUser() {
-	fmt.Printf("works")
+// Query retrieves a list of existing users from the database, leaving out
+// the deleted ones.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	usrs, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return usrs, nil
+}
+
+// QueryIncludingDeleted is like Query but also retrieves the deleted users.
+// Meant for admin tooling.
+func (c *Core) QueryIncludingDeleted(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
+	usrs, err := c.storer.QueryIncludingDeleted(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return usrs, nil
+}
+
+// Search retrieves the users whose name or email contain term, ignoring the
+// case. An empty term returns the same listing as Query without a filter.
+func (c *Core) Search(ctx context.Context, term string, pageNumber int, rowsPerPage int) ([]User, error) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return c.Query(ctx, QueryFilter{}, DefaultOrderBy, pageNumber, rowsPerPage)
+	}
+
+	usrs, err := c.storer.Search(ctx, term, DefaultOrderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	return usrs, nil
+}
+
+// QueryByCursor retrieves up to limit users ordered by id, starting after
+// afterID. Use uuid.Nil to get the first page and the returned cursor as
+// afterID for the next one. The cursor is uuid.Nil when there are no more
+// users. Unlike Query, the cost doesn't grow with the page number.
+func (c *Core) QueryByCursor(ctx context.Context, afterID uuid.UUID, limit int) ([]User, uuid.UUID, error) {
+	if limit < 1 {
+		return nil, uuid.Nil, fmt.Errorf("limit must be at least 1: %d", limit)
+	}
+
+	// One more row than requested tells if there is a next page.
+	usrs, err := c.storer.QueryByCursor(ctx, afterID, limit+1)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("query: afterID[%s]: %w", afterID, err)
+	}
+
+	if len(usrs) <= limit {
+		return usrs, uuid.Nil, nil
+	}
+
+	usrs = usrs[:limit]
+
+	return usrs, usrs[limit-1].ID, nil
+}
+
+// Count returns the total number of users matching the filter, leaving out
+// the deleted ones.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	count, err := c.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+
+	return count, nil
+}
+
+// QueryByID gets the specified user from the database. Deleted users are
+// reported as not found.
+func (c *Core) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
+	usr, err := c.storer.QueryByID(ctx, userID)
+	if err != nil {
+		return User{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return usr, nil
+}
+
+// Delete soft deletes the specified user by setting its deleted_at column, so
+// the row is kept for the admin tooling. ErrNotFound is returned when the
+// user doesn't exist or is already deleted.
+func (c *Core) Delete(ctx context.Context, userID uuid.UUID) error {
+	now := timecl.Now()
+
+	if err := c.storer.Delete(ctx, userID, now); err != nil {
+		return fmt.Errorf("delete: userID[%s]: %w", userID, err)
+	}
+
+	c.publish(ctx, UserDeleted{UserID: userID, Timestamp: now})
+
+	return nil
+}
+
+// Authenticate finds the user by email and checks the password against its
+// hash. ErrAuthenticationFailure is returned both when the email is unknown
+// and when the password doesn't match, so callers can't tell which.
+func (c *Core) Authenticate(ctx context.Context, email string, password string) (User, error) {
+	usr, err := c.storer.QueryByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			// The password is still checked against a hash of the same cost,
+			// so the response time doesn't tell the email is unknown.
+			bcrypt.CompareHashAndPassword(dummyHash(c.bcryptCost), []byte(password))
+			return User{}, fmt.Errorf("authenticate: %w", ErrAuthenticationFailure)
+		}
+		return User{}, fmt.Errorf("authenticate: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
+		return User{}, fmt.Errorf("authenticate: %w", ErrAuthenticationFailure)
+	}
+
+	return usr, nil
+}
+
+// dummyHashes caches the hashes returned by dummyHash by their cost.
+var dummyHashes sync.Map
+
+// dummyHash returns a hash of a random password generated with the cost,
+// compared when the email is unknown so it takes as long as a wrong password.
+func dummyHash(cost int) []byte {
+	if hash, ok := dummyHashes.Load(cost); ok {
+		return hash.([]byte)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), cost)
+	if err != nil {
+		return nil
+	}
+
+	actual, _ := dummyHashes.LoadOrStore(cost, hash)
+
+	return actual.([]byte)
+}
+
+// publish sends the event to the bus. Within a transaction the event is held
+// until it commits, so a rolled back change is never published. The change
+// is already stored at that point, so a failure is logged instead of
+// failing the operation.
+func (c *Core) publish(ctx context.Context, e event.Event) {
+	send := func() {
+		if err := c.bus.Publish(ctx, e); err != nil {
+			c.logger.Error(ctx, "publish event", "event", e.Name(), "ERROR", err)
+		}
+	}
+
+	if !transaction.OnCommit(ctx, send) {
+		send()
+	}
 }
@@ -0,0 +1,632 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/core/event"
+	"github.com/Yeremi528/laboratorio/business/core/user"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/Yeremi528/laboratorio/business/data/transaction"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newCore returns a user core backed by sqlmock.
+func newCore(t *testing.T) (*user.Core, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("creating sqlmock: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	return user.NewCore(log, sqlx.NewDb(db, "pgx"), user.WithBcryptCost(bcrypt.MinCost)), mock
+}
+
+// newMemCore returns a user core backed by an in-memory store.
+func newMemCore(t *testing.T) *user.Core {
+	t.Helper()
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	return user.NewCoreWithStore(log, user.NewMemStore(), user.WithBcryptCost(bcrypt.MinCost))
+}
+
+func TestCreateUser(t *testing.T) {
+	core, mock := newCore(t)
+
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	nu := validNewUser()
+	usr, err := core.CreateUser(context.Background(), nu)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if usr.Email != nu.Email || !usr.Enabled || usr.DateCreated.IsZero() {
+		t.Fatalf("unexpected user %+v", usr)
+	}
+
+	if usr.RUT != "12345678-5" {
+		t.Fatalf("expected the rut in its canonical format, got %s", usr.RUT)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(nu.Password)); err != nil {
+		t.Fatalf("expected the password to be hashed: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recordBus is an event.Bus that keeps the published events.
+type recordBus struct {
+	events []event.Event
+}
+
+func (b *recordBus) Publish(ctx context.Context, e event.Event) error {
+	b.events = append(b.events, e)
+	return nil
+}
+
+func TestCreateUserPublishesEvent(t *testing.T) {
+	bus := recordBus{}
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+	core := user.NewCoreWithStore(log, user.NewMemStore(), user.WithBcryptCost(bcrypt.MinCost), user.WithEventBus(&bus))
+
+	usr, err := core.CreateUser(context.Background(), validNewUser())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(bus.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(bus.events))
+	}
+
+	created, ok := bus.events[0].(user.UserCreated)
+	if !ok {
+		t.Fatalf("expected a UserCreated event, got %T", bus.events[0])
+	}
+
+	if created.UserID != usr.ID || created.Timestamp.IsZero() || created.Name() != user.EventUserCreated {
+		t.Fatalf("unexpected event %+v", created)
+	}
+
+	nu := validNewUser()
+	nu.RUT = "11.111.111-1"
+	if _, err := core.CreateUser(context.Background(), nu); !errors.Is(err, user.ErrUniqueEmail) {
+		t.Fatalf("expected ErrUniqueEmail, got %v", err)
+	}
+
+	if len(bus.events) != 1 {
+		t.Fatalf("expected no event for a failed create, got %d events", len(bus.events))
+	}
+}
+
+// nopTx is a transaction that does nothing, the stores of the tests don't
+// run under it.
+type nopTx struct{}
+
+func (nopTx) Commit() error   { return nil }
+func (nopTx) Rollback() error { return nil }
+
+func TestEventsPublishedOnCommit(t *testing.T) {
+	bus := recordBus{}
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+	core := user.NewCoreWithStore(log, user.NewMemStore(), user.WithBcryptCost(bcrypt.MinCost), user.WithEventBus(&bus))
+
+	ctx := transaction.Set(context.Background(), nopTx{})
+
+	usr, err := core.CreateUser(ctx, validNewUser())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := core.Delete(ctx, usr.ID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(bus.events) != 0 {
+		t.Fatalf("expected no event before the commit, got %d", len(bus.events))
+	}
+
+	transaction.Committed(ctx)
+
+	if len(bus.events) != 2 || bus.events[0].Name() != user.EventUserCreated || bus.events[1].Name() != user.EventUserDeleted {
+		t.Fatalf("expected the events in order once committed, got %v", bus.events)
+	}
+}
+
+func TestCreateUserDuplicatedEmail(t *testing.T) {
+	core, mock := newCore(t)
+
+	mock.ExpectExec("INSERT INTO users").WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_active_key"})
+
+	if _, err := core.CreateUser(context.Background(), validNewUser()); !errors.Is(err, user.ErrUniqueEmail) {
+		t.Fatalf("expected ErrUniqueEmail, got %v", err)
+	}
+}
+
+func TestCreateUserDuplicatedID(t *testing.T) {
+	core, mock := newCore(t)
+
+	mock.ExpectExec("INSERT INTO users").WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_pkey"})
+
+	_, err := core.CreateUser(context.Background(), validNewUser())
+	if err == nil || errors.Is(err, user.ErrUniqueEmail) {
+		t.Fatalf("expected only the email index to be reported as ErrUniqueEmail, got %v", err)
+	}
+
+	if !errors.Is(err, pgx.ErrDBDuplicatedEntry) {
+		t.Fatalf("expected ErrDBDuplicatedEntry, got %v", err)
+	}
+}
+
+func TestCreateUserInvalid(t *testing.T) {
+	core, mock := newCore(t)
+
+	nu := validNewUser()
+	nu.Email = "not an email"
+
+	if _, err := core.CreateUser(context.Background(), nu); !validate.IsFieldErrors(err) {
+		t.Fatalf("expected field errors, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected no query for an invalid user: %s", err)
+	}
+}
+
+func TestCoreWithReplicas(t *testing.T) {
+	newDB := func() (*sqlx.DB, sqlmock.Sqlmock) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("creating sqlmock: %s", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		return sqlx.NewDb(db, "pgx"), mock
+	}
+
+	primary, primaryMock := newDB()
+	replica, replicaMock := newDB()
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+	core := user.NewCoreWithReplicas(log, pgx.NewDB(primary, replica))
+
+	replicaMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	primaryMock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := core.Count(context.Background(), user.QueryFilter{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := core.CreateUser(context.Background(), validNewUser()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, mock := range []sqlmock.Sqlmock{primaryMock, replicaMock} {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDeleteSoft(t *testing.T) {
+	core, mock := newCore(t)
+
+	id := uuid.New()
+	columns := []string{"user_id", "name", "email", "rut", "roles", "password_hash", "department", "enabled", "date_created", "date_updated", "deleted_at"}
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE\s+users\s+SET\s+deleted_at`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`WHERE deleted_at IS NULL`).WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(`FROM\s+users\s+ORDER BY`).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(id, "Jane", "jane@example.com", "12.345.678-5", "{USER}", "hash", nil, true, now, now, now))
+
+	if err := core.Delete(context.Background(), id); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	usrs, err := core.Query(context.Background(), user.QueryFilter{}, user.DefaultOrderBy, 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(usrs) != 0 {
+		t.Fatalf("expected the deleted user to be left out, got %d users", len(usrs))
+	}
+
+	usrs, err = core.QueryIncludingDeleted(context.Background(), user.QueryFilter{}, user.DefaultOrderBy, 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(usrs) != 1 || usrs[0].ID != id || usrs[0].DateDeleted.IsZero() {
+		t.Fatalf("expected the deleted user through the admin path, got %+v", usrs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	bus := recordBus{}
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+	mem := user.NewCoreWithStore(log, user.NewMemStore(), user.WithBcryptCost(bcrypt.MinCost), user.WithEventBus(&bus))
+
+	usr, err := mem.CreateUser(context.Background(), validNewUser())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := mem.Delete(context.Background(), usr.ID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, id := range []uuid.UUID{usr.ID, uuid.New()} {
+		if err := mem.Delete(context.Background(), id); !errors.Is(err, user.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+
+	if len(bus.events) != 2 {
+		t.Fatalf("expected no event for the failed deletes, got %d events", len(bus.events))
+	}
+
+	core, mock := newCore(t)
+	mock.ExpectExec(`UPDATE\s+users\s+SET\s+deleted_at`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := core.Delete(context.Background(), uuid.New()); !errors.Is(err, user.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound when no row is affected, got %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	id := uuid.New()
+	columns := []string{"user_id", "name", "email", "rut", "roles", "password_hash", "department", "enabled", "date_created", "date_updated", "deleted_at"}
+	now := time.Now()
+
+	row := func(name string) *sqlmock.Rows {
+		return sqlmock.NewRows(columns).AddRow(id, name, "jane@example.com", "12.345.678-5", "{USER}", "hash", nil, true, now, now, nil)
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		core := newMemCore(t)
+
+		created, err := core.CreateUser(context.Background(), validNewUser())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		name := "Janet"
+		usr, err := core.Update(context.Background(), created.ID, user.UpdateUser{Name: &name})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if usr.Name != name || usr.Email != created.Email || usr.RUT != created.RUT {
+			t.Fatalf("expected only the name to change, got %+v", usr)
+		}
+
+		stored, err := core.QueryByID(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if stored.Name != name {
+			t.Fatalf("expected the update to be stored, got %+v", stored)
+		}
+	})
+
+	t.Run("only provided columns", func(t *testing.T) {
+		core, mock := newCore(t)
+
+		mock.ExpectExec(`SET\s+name = \$1, date_updated = \$2\s+WHERE`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT").WillReturnRows(row("Janet"))
+
+		name := "Janet"
+		usr, err := core.Update(context.Background(), id, user.UpdateUser{Name: &name})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if usr.Name != name {
+			t.Fatalf("expected the stored user, got %+v", usr)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("expected a single partial update: %s", err)
+		}
+	})
+
+	t.Run("concurrent fields", func(t *testing.T) {
+		core := newMemCore(t)
+
+		created, err := core.CreateUser(context.Background(), validNewUser())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		name := "Janet"
+		department := "Finanzas"
+
+		var wg sync.WaitGroup
+		for _, uu := range []user.UpdateUser{{Name: &name}, {Department: &department}} {
+			wg.Add(1)
+			go func(uu user.UpdateUser) {
+				defer wg.Done()
+				if _, err := core.Update(context.Background(), created.ID, uu); err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+			}(uu)
+		}
+		wg.Wait()
+
+		stored, err := core.QueryByID(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if stored.Name != name || stored.Department != department {
+			t.Fatalf("expected both updates to be kept, got %+v", stored)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		core := newMemCore(t)
+
+		name := "Janet"
+		if _, err := core.Update(context.Background(), uuid.New(), user.UpdateUser{Name: &name}); !errors.Is(err, user.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		core, mock := newCore(t)
+
+		mock.ExpectQuery("SELECT").WillReturnRows(row("Jane"))
+
+		usr, err := core.Update(context.Background(), id, user.UpdateUser{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if usr.Name != "Jane" {
+			t.Fatalf("expected the existing user, got %+v", usr)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("expected no update for an empty update: %s", err)
+		}
+	})
+
+	t.Run("duplicated email", func(t *testing.T) {
+		core, mock := newCore(t)
+
+		mock.ExpectExec("UPDATE").WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_active_key"})
+
+		email := "taken@example.com"
+		if _, err := core.Update(context.Background(), id, user.UpdateUser{Email: &email}); !errors.Is(err, user.ErrUniqueEmail) {
+			t.Fatalf("expected ErrUniqueEmail, got %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		core, _ := newCore(t)
+
+		email := "not an email"
+		if _, err := core.Update(context.Background(), id, user.UpdateUser{Email: &email}); !validate.IsFieldErrors(err) {
+			t.Fatalf("expected field errors, got %v", err)
+		}
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("gophers123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hashing: %s", err)
+	}
+
+	columns := []string{"user_id", "name", "email", "rut", "roles", "password_hash", "department", "enabled", "date_created", "date_updated", "deleted_at"}
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		password string
+		found    bool
+		err      error
+	}{
+		{name: "valid", password: "gophers123", found: true},
+		{name: "wrong password", password: "gophers", found: true, err: user.ErrAuthenticationFailure},
+		{name: "unknown email", password: "gophers123", err: user.ErrAuthenticationFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mock := newCore(t)
+
+			rows := sqlmock.NewRows(columns)
+			if tt.found {
+				rows.AddRow(uuid.New(), "Ana", "ana@example.com", "12345678-5", "{USER}", string(hash), nil, true, now, now, nil)
+			}
+			mock.ExpectQuery(`email = \$1 AND deleted_at IS NULL`).WithArgs("ana@example.com").WillReturnRows(rows)
+
+			usr, err := core.Authenticate(context.Background(), "ana@example.com", tt.password)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+
+			if tt.err == nil && usr.Email != "ana@example.com" {
+				t.Fatalf("expected the authenticated user, got %+v", usr)
+			}
+		})
+	}
+}
+
+func TestAuthenticateUnknownEmailTiming(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+	core := user.NewCoreWithStore(log, user.NewMemStore(), user.WithBcryptCost(bcrypt.DefaultCost))
+	ctx := context.Background()
+
+	nu := validNewUser()
+	if _, err := core.CreateUser(ctx, nu); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	elapsed := func(email string) time.Duration {
+		start := time.Now()
+		if _, err := core.Authenticate(ctx, email, "wrong password"); !errors.Is(err, user.ErrAuthenticationFailure) {
+			t.Fatalf("expected ErrAuthenticationFailure, got %v", err)
+		}
+		return time.Since(start)
+	}
+
+	wrongPassword := elapsed(nu.Email)
+	unknownEmail := elapsed("nobody@example.com")
+
+	// Without the dummy comparison the unknown email returns in microseconds.
+	if unknownEmail < wrongPassword/4 {
+		t.Fatalf("expected the unknown email to take about as long as a wrong password, got %s and %s", unknownEmail, wrongPassword)
+	}
+}
+
+func TestQueryByCursor(t *testing.T) {
+	core := newMemCore(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		nu := validNewUser()
+		nu.Email = fmt.Sprintf("user%d@example.com", i)
+		if _, err := core.CreateUser(ctx, nu); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	var pages [][]user.User
+	cursor := uuid.Nil
+	for {
+		usrs, next, err := core.QueryByCursor(ctx, cursor, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		pages = append(pages, usrs)
+
+		if next == uuid.Nil {
+			break
+		}
+		cursor = next
+	}
+
+	if len(pages) != 3 || len(pages[2]) != 1 {
+		t.Fatalf("expected pages of 2, 2 and 1 users, got %d pages", len(pages))
+	}
+
+	var last string
+	for _, usrs := range pages {
+		for _, usr := range usrs {
+			if usr.ID.String() <= last {
+				t.Fatalf("expected the users ordered by id without repeats")
+			}
+			last = usr.ID.String()
+		}
+	}
+
+	if _, _, err := core.QueryByCursor(ctx, uuid.Nil, 0); err == nil {
+		t.Fatal("expected an error for a limit of 0")
+	}
+}
+
+func TestQueryByCursorKeyset(t *testing.T) {
+	core, mock := newCore(t)
+
+	after := uuid.New()
+	columns := []string{"user_id", "name", "email", "rut", "roles", "password_hash", "department", "enabled", "date_created", "date_updated", "deleted_at"}
+
+	mock.ExpectQuery(`user_id > \$1 AND deleted_at IS NULL\s+ORDER BY\s+user_id\s+LIMIT \$2`).
+		WithArgs(after.String(), 11).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	usrs, next, err := core.QueryByCursor(context.Background(), after, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(usrs) != 0 || next != uuid.Nil {
+		t.Fatalf("expected no users and an empty cursor, got %d users and %s", len(usrs), next)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("bound term", func(t *testing.T) {
+		core, mock := newCore(t)
+
+		columns := []string{"user_id", "name", "email", "rut", "roles", "password_hash", "department", "enabled", "date_created", "date_updated", "deleted_at"}
+
+		// The wildcards in the term are escaped and the term never reaches the SQL.
+		mock.ExpectQuery(`\(name ILIKE \$1 OR email ILIKE \$2\) ORDER BY`).
+			WithArgs(`%50\%' OR 1=1 --%`, `%50\%' OR 1=1 --%`, 0, 10).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		if _, err := core.Search(context.Background(), "50%' OR 1=1 --", 1, 10); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("name or email", func(t *testing.T) {
+		core := newMemCore(t)
+		ctx := context.Background()
+
+		for _, nu := range []user.NewUser{
+			{Name: "Ana Rojas", Email: "ana@example.com", RUT: "12.345.678-5", Password: "gophers123"},
+			{Name: "Bruno Díaz", Email: "bruno.rojas@example.com", RUT: "11.111.111-1", Password: "gophers123"},
+			{Name: "Carla Soto", Email: "carla@example.com", RUT: "22.222.222-2", Password: "gophers123"},
+		} {
+			if _, err := core.CreateUser(ctx, nu); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		}
+
+		usrs, err := core.Search(ctx, "ROJAS", 1, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(usrs) != 2 {
+			t.Fatalf("expected 2 users matching by name or email, got %d", len(usrs))
+		}
+
+		usrs, err = core.Search(ctx, " ", 1, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(usrs) != 3 {
+			t.Fatalf("expected the normal listing for an empty term, got %d users", len(usrs))
+		}
+	})
+}
@@ -1,72 +1,180 @@
+// Package dbmigrate contains the database schema, migrations and seeding data.
 package dbmigrate
 
-// import (
-// 	"context"
-// 	"database/sql"
-// 	_ "embed"
-// 	"errors"
-// 	"fmt"
-
-// 	"github.com/ardanlabs/darwin"
-// 	"github.com/ardanlabs/darwin/v3/dialects/postgres"
-// 	"github.com/ardanlabs/darwin/v3/drivers/generic"
-// 	"github.com/jmoiron/sqlx"
-// )
-
-// var (
-// 	//go:embed sql/migrate.sql
-// 	migrateDoc string
-
-// 	//go:embed sql/seed.sql
-// 	seedDoc string
-// )
-
-// // Migrate attempts to bring the database up to date with the migrations
-// // defined in this package.
-// func Migrate(ctx context.Context, db *sqlx.DB) error {
-// 	if err := database.StatusCheck(ctx, db); err != nil {
-// 		return fmt.Errorf("status check database: %w", err)
-// 	}
-// 	databa
-
-// 	driver, err := generic.New(db.DB, postgres.Dialect{})
-// 	if err != nil {
-// 		return fmt.Errorf("construct darwin driver: %w", err)
-// 	}
-
-// 	d := darwin.New(driver, darwin.ParseMigrations(migrateDoc))
-// 	return d.Migrate()
-// }
-
-// // Seed runs the seed document defined in this package against db. The queries
-// // are run in a transaction and rolled back if any fail.
-// func Seed(ctx context.Context, db *sqlx.DB) (err error) {
-// 	if err := database.StatusCheck(ctx, db); err != nil {
-// 		return fmt.Errorf("status check database: %w", err)
-// 	}
-
-// 	tx, err := db.Begin()
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	defer func() {
-// 		if errTx := tx.Rollback(); errTx != nil {
-// 			if errors.Is(errTx, sql.ErrTxDone) {
-// 				return
-// 			}
-// 			err = fmt.Errorf("rollback: %w", errTx)
-// 			return
-// 		}
-// 	}()
-
-// 	if _, err := tx.Exec(seedDoc); err != nil {
-// 		return fmt.Errorf("exec: %w", err)
-// 	}
-
-// 	if err := tx.Commit(); err != nil {
-// 		return fmt.Errorf("commit: %w", err)
-// 	}
-
-// 	return nil
-// }
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	//go:embed sql/migrate/*.sql
+	migrateFS embed.FS
+
+	//go:embed sql/seed/*.sql
+	seedFS embed.FS
+)
+
+// Migrations holds the migration documents defined in this package.
+var Migrations = mustSub(migrateFS, "sql/migrate")
+
+// Seeds holds the seed documents defined in this package.
+var Seeds = mustSub(seedFS, "sql/seed")
+
+// migrationsTable tracks the versions already applied to the database.
+const migrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    TEXT      NOT NULL,
+	applied_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+
+	PRIMARY KEY (version)
+)`
+
+// Migrate attempts to bring the database up to date with the .sql files
+// found at the root of fsys. Files are applied in lexical order, so they
+// should be prefixed with a sortable version like 0001_. Every migration runs
+// in its own transaction and the first failure stops the process.
+func Migrate(ctx context.Context, db *sqlx.DB, fsys fs.FS) error {
+	if err := pgx.StatusCheck(ctx, db); err != nil {
+		return fmt.Errorf("status check database: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, migrationsTable); err != nil {
+		return fmt.Errorf("create migrations table: %w", err)
+	}
+
+	files, err := sqlFiles(fsys)
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[string]bool)
+	var versions []string
+	if err := db.SelectContext(ctx, &versions, `SELECT version FROM schema_migrations`); err != nil {
+		return fmt.Errorf("query applied migrations: %w", err)
+	}
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	for _, file := range files {
+		if applied[file] {
+			continue
+		}
+
+		doc, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("read migration %s: %w", file, err)
+		}
+
+		err = inTran(ctx, db, func(tx *sqlx.Tx) error {
+			if _, err := tx.ExecContext(ctx, string(doc)); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, file)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// Seed runs the .sql files found at the root of fsys against db. All the files
+// are run in a single transaction which is rolled back if any of them fail.
+func Seed(ctx context.Context, db *sqlx.DB, fsys fs.FS) error {
+	if err := pgx.StatusCheck(ctx, db); err != nil {
+		return fmt.Errorf("status check database: %w", err)
+	}
+
+	files, err := sqlFiles(fsys)
+	if err != nil {
+		return err
+	}
+
+	return inTran(ctx, db, func(tx *sqlx.Tx) error {
+		for _, file := range files {
+			doc, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return fmt.Errorf("read seed %s: %w", file, err)
+			}
+
+			if _, err := tx.ExecContext(ctx, string(doc)); err != nil {
+				return fmt.Errorf("exec seed %s: %w", file, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// =============================================================================
+
+// inTran executes fn inside a transaction that is committed when fn succeeds
+// and rolled back otherwise. A failed rollback is joined to the error of fn.
+func inTran(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer func() {
+		if errTx := tx.Rollback(); errTx != nil {
+			if errors.Is(errTx, sql.ErrTxDone) {
+				return
+			}
+			err = errors.Join(err, fmt.Errorf("rollback: %w", errTx))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// sqlFiles returns the names of the .sql files at the root of fsys sorted
+// in lexical order.
+func sqlFiles(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
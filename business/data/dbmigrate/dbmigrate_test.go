@@ -0,0 +1,103 @@
+package dbmigrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/data/dbmigrate"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestMigrate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_users.sql": {Data: []byte("CREATE TABLE users (user_id UUID)")},
+		"0002_roles.sql": {Data: []byte("ALTER TABLE users ADD roles TEXT[]")},
+		"0003_index.sql": {Data: []byte("CREATE INDEX users_roles ON users (roles)")},
+		"README.md":      {Data: []byte("not a migration")},
+		"seed/extra.sql": {Data: []byte("SELECT 1")},
+	}
+
+	errMigration := errors.New("syntax error")
+	errRollback := errors.New("connection lost")
+
+	tests := []struct {
+		name    string
+		applied []string
+		expect  func(mock sqlmock.Sqlmock)
+		check   func(err error) bool
+	}{
+		{
+			name: "apply",
+			expect: func(mock sqlmock.Sqlmock) {
+				for _, file := range []string{"0001_users.sql", "0002_roles.sql", "0003_index.sql"} {
+					mock.ExpectBegin()
+					mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(file).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				}
+			},
+			check: func(err error) bool { return err == nil },
+		},
+		{
+			name:    "skip applied",
+			applied: []string{"0001_users.sql", "0002_roles.sql"},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE INDEX").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO schema_migrations").WithArgs("0003_index.sql").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			check: func(err error) bool { return err == nil },
+		},
+		{
+			name: "failure rolls back",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE TABLE users").WillReturnError(errMigration)
+				mock.ExpectRollback()
+			},
+			check: func(err error) bool { return errors.Is(err, errMigration) },
+		},
+		{
+			name: "failed rollback",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE TABLE users").WillReturnError(errMigration)
+				mock.ExpectRollback().WillReturnError(errRollback)
+			},
+			check: func(err error) bool { return errors.Is(err, errMigration) && errors.Is(err, errRollback) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			if err != nil {
+				t.Fatalf("creating sqlmock: %s", err)
+			}
+			defer db.Close()
+
+			applied := sqlmock.NewRows([]string{"version"})
+			for _, version := range tt.applied {
+				applied.AddRow(version)
+			}
+
+			mock.ExpectQuery("SELECT true").WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(applied)
+			tt.expect(mock)
+
+			err = dbmigrate.Migrate(context.Background(), sqlx.NewDb(db, "pgx"), fsys)
+			if !tt.check(err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
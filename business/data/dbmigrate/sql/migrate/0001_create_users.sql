@@ -1,4 +1,3 @@
--- Version: 1.01
 -- Description: Create table users
 CREATE TABLE users (
 	user_id       UUID        NOT NULL,
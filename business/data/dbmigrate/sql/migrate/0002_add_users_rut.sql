@@ -0,0 +1,2 @@
+-- Description: Add the rut column to users
+ALTER TABLE users ADD COLUMN rut TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Description: Add the deleted_at column to soft delete users
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL;
@@ -0,0 +1,3 @@
+-- Description: Only keep the emails of the users that aren't deleted unique
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_active_key ON users (email) WHERE deleted_at IS NULL;
@@ -0,0 +1,52 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AcquireTimeout waits up to timeout for a connection of the pool and runs fn
+// with it. database/sql blocks while the pool is exhausted until the context
+// is done, so instead of failing with the generic deadline of the request,
+// ErrPoolExhausted is returned as soon as the timeout elapses. The timeout
+// only applies to the acquisition, the queries run by fn use ctx.
+//
+//	err := pgx.AcquireTimeout(ctx, db, 100*time.Millisecond, func(ctx context.Context, db sqlx.ExtContext) error {
+//		return pgx.RunQuery(ctx, db, q, &dest)
+//	})
+func AcquireTimeout(ctx context.Context, db *sqlx.DB, timeout time.Duration, fn func(ctx context.Context, db sqlx.ExtContext) error) error {
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := db.Connx(actx)
+	if err != nil {
+		if errors.Is(actx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return fmt.Errorf("%w: no connection within %s", ErrPoolExhausted, timeout)
+		}
+		return err
+	}
+	defer conn.Close()
+
+	return fn(ctx, &extConn{Conn: conn, driverName: db.DriverName()})
+}
+
+// extConn adapts a single connection to the sqlx.ExtContext interface used
+// by the helpers of this package.
+type extConn struct {
+	*sqlx.Conn
+	driverName string
+}
+
+// DriverName returns the name of the driver of the connection.
+func (c *extConn) DriverName() string {
+	return c.driverName
+}
+
+// BindNamed binds a query using the bind type of the driver.
+func (c *extConn) BindNamed(query string, arg any) (string, []any, error) {
+	return sqlx.BindNamed(sqlx.BindType(c.driverName), query, arg)
+}
@@ -0,0 +1,47 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestAcquireTimeout(t *testing.T) {
+	db, mock := newMock(t)
+	db.SetMaxOpenConns(1)
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana"))
+
+	// Hold the only connection of the pool.
+	held, err := db.Connx(context.Background())
+	if err != nil {
+		t.Fatalf("acquiring: %s", err)
+	}
+
+	err = pgx.AcquireTimeout(context.Background(), db, 20*time.Millisecond, func(ctx context.Context, db sqlx.ExtContext) error {
+		t.Fatal("expected fn not to run while the pool is exhausted")
+		return nil
+	})
+	if !errors.Is(err, pgx.ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+
+	held.Close()
+
+	var usr user
+	err = pgx.AcquireTimeout(context.Background(), db, time.Second, func(ctx context.Context, db sqlx.ExtContext) error {
+		return pgx.RunQuery(ctx, db, `SELECT user_id, name FROM users`, &usr)
+	})
+	if err != nil {
+		t.Fatalf("expected the released connection to be used, got %v", err)
+	}
+
+	if usr.Name != "Ana" {
+		t.Fatalf("expected Ana, got %+v", usr)
+	}
+}
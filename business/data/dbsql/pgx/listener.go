@@ -0,0 +1,179 @@
+package pgx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	jpgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// Notification represents a message received from a postgres NOTIFY.
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// Listener provides support for receiving postgres notifications. Every call
+// to Listen holds a dedicated connection from the pool until its context is
+// cancelled.
+type Listener struct {
+	log           *logger.Logger
+	db            *sqlx.DB
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+}
+
+// NewListener constructs a listener that uses connections from the db pool.
+func NewListener(log *logger.Logger, db *sqlx.DB) *Listener {
+	return &Listener{
+		log:           log,
+		db:            db,
+		retryDelay:    time.Second,
+		maxRetryDelay: 30 * time.Second,
+	}
+}
+
+// Listen subscribes to the channel and returns the notifications received on
+// it. When the underlying connection drops, a new one is acquired and the
+// subscription is restored. The returned channel is closed once ctx is
+// cancelled and the subscription has been removed, or when a failure that
+// isn't a connection problem happens, which is logged.
+func (l *Listener) Listen(ctx context.Context, channel string) (<-chan Notification, error) {
+	conn, err := l.connect(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("listen %s: %w", channel, err)
+	}
+
+	ch := make(chan Notification)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			n, err := wait(ctx, conn)
+			if err != nil {
+				if ctx.Err() != nil {
+					unlisten(conn, channel)
+					return
+				}
+
+				conn.Close()
+
+				if !errors.Is(err, driver.ErrBadConn) {
+					l.log.Error(ctx, "listener stopped", "channel", channel, "ERROR", err)
+					return
+				}
+
+				l.log.Warn(ctx, "listener connection lost", "channel", channel)
+
+				if conn = l.reconnect(ctx, channel); conn == nil {
+					return
+				}
+				continue
+			}
+
+			select {
+			case ch <- n:
+			case <-ctx.Done():
+				unlisten(conn, channel)
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// connect acquires a dedicated connection and subscribes it to the channel.
+func (l *Listener) connect(ctx context.Context, channel string) (*sql.Conn, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "LISTEN "+jpgx.Identifier{channel}.Sanitize()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// reconnect keeps trying to subscribe to the channel with an increasing delay
+// between attempts, up to maxRetryDelay. It returns nil when ctx is cancelled
+// first or when the database rejects the subscription, since retrying won't
+// help.
+func (l *Listener) reconnect(ctx context.Context, channel string) *sql.Conn {
+	for attempts := 1; ; attempts++ {
+		delay := min(time.Duration(attempts)*l.retryDelay, l.maxRetryDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		conn, err := l.connect(ctx, channel)
+		if err == nil {
+			l.log.Info(ctx, "listener reconnected", "channel", channel, "attempts", attempts)
+			return conn
+		}
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			l.log.Error(ctx, "listener stopped", "channel", channel, "ERROR", err)
+			return nil
+		}
+
+		l.log.Warn(ctx, "listener reconnect failed", "channel", channel, "attempts", attempts, "ERROR", err)
+	}
+}
+
+// wait blocks until a notification is received on the connection. A broken
+// connection is reported as bad so the pool discards it.
+func wait(ctx context.Context, conn *sql.Conn) (Notification, error) {
+	var n Notification
+
+	err := conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+
+		pn, err := c.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			return driver.ErrBadConn
+		}
+
+		n = Notification{Channel: pn.Channel, Payload: pn.Payload}
+
+		return nil
+	})
+
+	return n, err
+}
+
+// unlisten removes the subscription and returns the connection to the pool.
+// The connection is discarded if the subscription can't be removed so no
+// pooled connection keeps listening.
+func unlisten(conn *sql.Conn, channel string) {
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, "UNLISTEN "+jpgx.Identifier{channel}.Sanitize()); err != nil {
+		conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+}
@@ -0,0 +1,46 @@
+package pgx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yeremi528/laboratorio/business/data/order"
+)
+
+// OrderBy constructs an ORDER BY clause from a comma separated list of
+// "field:direction" tokens, like "name:asc,date_created:desc". Every field is
+// mapped to its column through the allowed map and the direction defaults to
+// ASC when omitted. Any unknown field or direction returns an error, so the
+// clause only ever contains values from the allowed map. An empty param
+// returns an empty clause.
+func OrderBy(param string, allowed map[string]string) (string, error) {
+	param = strings.TrimSpace(param)
+	if param == "" {
+		return "", nil
+	}
+
+	tokens := strings.Split(param, ",")
+	terms := make([]string, 0, len(tokens))
+
+	for _, token := range tokens {
+		field, direction, _ := strings.Cut(strings.TrimSpace(token), ":")
+
+		column, exists := allowed[strings.TrimSpace(field)]
+		if !exists {
+			return "", fmt.Errorf("unknown order field: %q", field)
+		}
+
+		direction = strings.ToUpper(strings.TrimSpace(direction))
+		switch direction {
+		case "":
+			direction = order.ASC
+		case order.ASC, order.DESC:
+		default:
+			return "", fmt.Errorf("unknown order direction: %q", direction)
+		}
+
+		terms = append(terms, column+" "+direction)
+	}
+
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,49 @@
+package pgx_test
+
+import (
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+)
+
+func TestOrderBy(t *testing.T) {
+	allowed := map[string]string{
+		"name":        "name",
+		"dateCreated": "date_created",
+	}
+
+	tests := []struct {
+		name    string
+		param   string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", param: "", want: ""},
+		{name: "single field", param: "name", want: "ORDER BY name ASC"},
+		{name: "multiple fields", param: "name:desc, dateCreated:asc", want: "ORDER BY name DESC, date_created ASC"},
+		{name: "unknown field", param: "password", wantErr: true},
+		{name: "unknown direction", param: "name:sideways", wantErr: true},
+		{name: "injection in field", param: "name; DROP TABLE users", wantErr: true},
+		{name: "injection in direction", param: "name:asc; DROP TABLE users", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pgx.OrderBy(tt.param, allowed)
+			if tt.wantErr {
+				if err == nil || got != "" {
+					t.Fatalf("expected an error and no clause, got %q, %v", got, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
 )
 
 const (
@@ -21,13 +23,59 @@ const (
 	undefinedTable  = "42P01"
 )
 
+// defaultBatchSize is the number of rows per statement used by RunBatchInsert
+// when no batch size is provided.
+const defaultBatchSize = 500
+
+// DefaultQueryTimeout bounds the helpers of this package when the context
+// they receive has no deadline, so a hung query can't hold a connection
+// forever. Callers with their own deadline are unaffected. Zero disables it.
+var DefaultQueryTimeout = 30 * time.Second
+
+// withQueryTimeout derives a context bounded by DefaultQueryTimeout when ctx
+// has no deadline.
+func withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || DefaultQueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, DefaultQueryTimeout)
+}
+
 // Set of error variables for CRUD operations.
 var (
 	ErrDBNotFound        = sql.ErrNoRows
 	ErrDBDuplicatedEntry = errors.New("duplicated entry")
 	ErrUndefinedTable    = errors.New("undefined table")
+	ErrMultipleRows      = errors.New("multiple rows returned")
+	ErrQueryTimeout      = errors.New("query timeout")
+	ErrPoolExhausted     = errors.New("connection pool exhausted")
 )
 
+// DuplicatedEntryError is returned when a statement violates a unique
+// constraint. It matches ErrDBDuplicatedEntry and tells the constraint, so
+// callers can tell which of the unique columns was repeated.
+type DuplicatedEntryError struct {
+	Constraint string
+}
+
+// Error implements the error interface.
+func (e *DuplicatedEntryError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDBDuplicatedEntry, e.Constraint)
+}
+
+// Is makes the error match ErrDBDuplicatedEntry.
+func (e *DuplicatedEntryError) Is(target error) bool {
+	return target == ErrDBDuplicatedEntry
+}
+
+// IsDuplicatedEntry checks if the error was caused by a violation of the
+// named unique constraint.
+func IsDuplicatedEntry(err error, constraint string) bool {
+	var de *DuplicatedEntryError
+	return errors.As(err, &de) && de.Constraint == constraint
+}
+
 // Config is the required properties to use the database.
 type Config struct {
 	User            string
@@ -110,12 +158,44 @@ func getSSLMode(cfg Config) (string, error) {
 }
 
 // RunQuery is a helper function for executing queries that return a
-// single value to be unmarshalled into a struct type.
+// single value to be unmarshalled into a struct type. Any additional rows
+// are ignored.
 func RunQuery(ctx context.Context, db sqlx.ExtContext, query string, dest any) error {
+	return runQuery(ctx, db, query, struct{}{}, dest, false)
+}
+
+// RunQueryStrict is like RunQuery but returns ErrMultipleRows when the query
+// returns more than one row. Use it for queries expected to be unique.
+func RunQueryStrict(ctx context.Context, db sqlx.ExtContext, query string, dest any) error {
+	return runQuery(ctx, db, query, struct{}{}, dest, true)
+}
+
+// RunQueryTimeout is like RunQuery but binds the named parameters from args
+// and gives up after the timeout, returning ErrQueryTimeout. Use it to keep
+// an expensive query from consuming the whole request deadline.
+func RunQueryTimeout[T any](ctx context.Context, db sqlx.ExtContext, query string, args any, timeout time.Duration) (T, error) {
+	qctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dest T
+	if err := runQuery(qctx, db, query, args, &dest, false); err != nil {
+		if errors.Is(qctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return dest, fmt.Errorf("%w: exceeded %s", ErrQueryTimeout, timeout)
+		}
+		return dest, err
+	}
+
+	return dest, nil
+}
+
+func runQuery(ctx context.Context, db sqlx.ExtContext, query string, data any, dest any, strict bool) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var rows *sqlx.Rows
 	var err error
 
-	rows, err = sqlx.NamedQueryContext(ctx, db, query, struct{}{})
+	rows, err = sqlx.NamedQueryContext(ctx, db, query, data)
 
 	if err != nil {
 		if pqerr, ok := err.(*pgconn.PgError); ok && pqerr.Code == undefinedTable {
@@ -126,6 +206,9 @@ func RunQuery(ctx context.Context, db sqlx.ExtContext, query string, dest any) e
 	defer rows.Close()
 
 	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
 		return ErrDBNotFound
 	}
 
@@ -133,16 +216,45 @@ func RunQuery(ctx context.Context, db sqlx.ExtContext, query string, dest any) e
 		return err
 	}
 
+	if strict {
+		if rows.Next() {
+			return ErrMultipleRows
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// RunNamedQuery is like RunQuery but binds the named parameters of the query
+// from data.
+func RunNamedQuery(ctx context.Context, db sqlx.ExtContext, query string, data any, dest any) error {
+	return runQuery(ctx, db, query, data, dest, false)
+}
+
 // RunQuerySlice is a helper function for executing queries that return a
 // collection of data to be unmarshalled into a slice.
 func RunQuerySlice[T any](ctx context.Context, db sqlx.ExtContext, query string, dest *[]T) error {
+	return runQuerySlice(ctx, db, query, struct{}{}, dest)
+}
+
+// RunNamedQuerySlice is like RunQuerySlice but binds the named parameters of
+// the query from data.
+func RunNamedQuerySlice[T any](ctx context.Context, db sqlx.ExtContext, query string, data any, dest *[]T) error {
+	return runQuerySlice(ctx, db, query, data, dest)
+}
+
+func runQuerySlice[T any](ctx context.Context, db sqlx.ExtContext, query string, data any, dest *[]T) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var rows *sqlx.Rows
 	var err error
 
-	rows, err = sqlx.NamedQueryContext(ctx, db, query, struct{}{})
+	rows, err = sqlx.NamedQueryContext(ctx, db, query, data)
 
 	if err != nil {
 		if pqerr, ok := err.(*pgconn.PgError); ok && pqerr.Code == undefinedTable {
@@ -160,6 +272,10 @@ func RunQuerySlice[T any](ctx context.Context, db sqlx.ExtContext, query string,
 		}
 		slice = append(slice, *v)
 	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
 	*dest = slice
 
 	return nil
@@ -167,19 +283,152 @@ func RunQuerySlice[T any](ctx context.Context, db sqlx.ExtContext, query string,
 
 // RunCUD is a helper function to execute a create, update, or delete operation.
 func RunCUD(ctx context.Context, db sqlx.ExtContext, query string, data any) error {
-	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
+	_, err := runCUD(ctx, db, query, data)
+	return err
+}
+
+// RunCUDAffected is like RunCUD but returns ErrDBNotFound when the statement
+// doesn't affect any row. Use it for updates and deletes of a single row.
+func RunCUDAffected(ctx context.Context, db sqlx.ExtContext, query string, data any) error {
+	result, err := runCUD(ctx, db, query, data)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if n == 0 {
+		return ErrDBNotFound
+	}
+
+	return nil
+}
+
+func runCUD(ctx context.Context, db sqlx.ExtContext, query string, data any) (sql.Result, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := sqlx.NamedExecContext(ctx, db, query, data)
+	if err != nil {
 		if pqerr, ok := err.(*pgconn.PgError); ok {
 			switch pqerr.Code {
 			case undefinedTable:
-				return ErrUndefinedTable
+				return nil, ErrUndefinedTable
 			case uniqueViolation:
-				return ErrDBDuplicatedEntry
+				return nil, &DuplicatedEntryError{Constraint: pqerr.ConstraintName}
 			}
 		}
-		return err
+		return nil, err
 	}
 
-	return nil
+	return result, nil
+}
+
+// upsertInsertedColumn is the column RunUpsertReturning reads to tell an
+// insert from an update.
+const upsertInsertedColumn = "inserted"
+
+// RunUpsertReturning is a helper function to execute an INSERT ... ON
+// CONFLICT ... DO UPDATE ... RETURNING query, scanning the resulting row into
+// dest. The query must also return "(xmax = 0) AS inserted", which is true
+// when the row was inserted and false when it was updated.
+func RunUpsertReturning(ctx context.Context, db sqlx.ExtContext, query string, data any, dest any) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := sqlx.NamedQueryContext(ctx, db, query, data)
+	if err != nil {
+		if pqerr, ok := err.(*pgconn.PgError); ok {
+			switch pqerr.Code {
+			case undefinedTable:
+				return false, ErrUndefinedTable
+			case uniqueViolation:
+				return false, &DuplicatedEntryError{Constraint: pqerr.ConstraintName}
+			}
+		}
+		return false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return false, err
+		}
+		return false, ErrDBNotFound
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return false, errors.New("dest must be a non nil pointer")
+	}
+	v = reflect.Indirect(v)
+
+	var inserted bool
+	var foundInserted bool
+	values := make([]any, len(columns))
+	traversals := rows.Mapper.TraversalsByName(v.Type(), columns)
+	for i, column := range columns {
+		if column == upsertInsertedColumn {
+			values[i] = &inserted
+			foundInserted = true
+			continue
+		}
+
+		if len(traversals[i]) == 0 {
+			return false, fmt.Errorf("missing destination name %s in %T", column, dest)
+		}
+		values[i] = reflectx.FieldByIndexes(v, traversals[i]).Addr().Interface()
+	}
+
+	if !foundInserted {
+		return false, fmt.Errorf("query must return the %s column", upsertInsertedColumn)
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return false, err
+	}
+
+	return inserted, nil
+}
+
+// RunBatchInsert is a helper function to insert a collection of rows using
+// multi-value INSERT statements. The baseQuery must be a named INSERT with a
+// single VALUES group, like "INSERT INTO t (a, b) VALUES (:a, :b)", which is
+// repeated once per row in the batch. Keep batchSize times the number of
+// columns under the postgres limit of 65535 parameters per statement. Every
+// batch gets its own DefaultQueryTimeout, so a large import isn't bounded by
+// the time of a single query. It returns the number of rows inserted, which
+// is accurate up to the failing batch when an error occurs.
+func RunBatchInsert[T any](ctx context.Context, db sqlx.ExtContext, baseQuery string, rows []T, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	var inserted int64
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+
+		result, err := runCUD(ctx, db, baseQuery, rows[start:end])
+		if err != nil {
+			return inserted, err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += n
+	}
+
+	return inserted, nil
 }
 
 // StatusCheck returns nil if it can successfully talk to the database. It
@@ -207,8 +456,18 @@ func StatusCheck(ctx context.Context, db *sqlx.DB) error {
 		return ctx.Err()
 	}
 
-	// Run a simple query to determine connectivity.
-	// Running this query forces a round trip through the database.
+	return roundTrip(ctx, db)
+}
+
+// Stats returns the state of the connection pool, like the number of open
+// connections and how many of them are in use by a query or transaction.
+func Stats(db *sqlx.DB) sql.DBStats {
+	return db.Stats()
+}
+
+// roundTrip runs a simple query to determine connectivity. Running this
+// query forces a round trip through the database.
+func roundTrip(ctx context.Context, db *sqlx.DB) error {
 	const q = `SELECT true`
 	var tmp bool
 	return db.QueryRowContext(ctx, q).Scan(&tmp)
@@ -0,0 +1,188 @@
+package pgx_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/jmoiron/sqlx"
+)
+
+type user struct {
+	ID   string `db:"user_id"`
+	Name string `db:"name"`
+}
+
+// newMock returns a sqlx.DB backed by sqlmock using the pgx bind type.
+func newMock(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("creating sqlmock: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return sqlx.NewDb(db, "pgx"), mock
+}
+
+func TestRunQueryStrict(t *testing.T) {
+	const q = `SELECT user_id, name FROM users`
+
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+		err  error
+	}{
+		{
+			name: "single row",
+			rows: sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana"),
+		},
+		{
+			name: "zero rows",
+			rows: sqlmock.NewRows([]string{"user_id", "name"}),
+			err:  pgx.ErrDBNotFound,
+		},
+		{
+			name: "multiple rows",
+			rows: sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana").AddRow("2", "Luis"),
+			err:  pgx.ErrMultipleRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMock(t)
+			mock.ExpectQuery("SELECT user_id, name FROM users").WillReturnRows(tt.rows)
+
+			var usr user
+			err := pgx.RunQueryStrict(context.Background(), db, q, &usr)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if tt.err == nil && usr.Name != "Ana" {
+				t.Fatalf("expected the first row to be scanned, got %+v", usr)
+			}
+		})
+	}
+}
+
+func TestRunQueryStrictRowError(t *testing.T) {
+	db, mock := newMock(t)
+
+	errRow := errors.New("connection lost")
+	rows := sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana").AddRow("2", "Luis").RowError(1, errRow)
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	var usr user
+	if err := pgx.RunQueryStrict(context.Background(), db, `SELECT user_id, name FROM users`, &usr); !errors.Is(err, errRow) {
+		t.Fatalf("expected the row error, got %v", err)
+	}
+}
+
+func TestRunQueryLenient(t *testing.T) {
+	db, mock := newMock(t)
+
+	rows := sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana").AddRow("2", "Luis")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	var usr user
+	if err := pgx.RunQuery(context.Background(), db, `SELECT user_id, name FROM users`, &usr); err != nil {
+		t.Fatalf("expected extra rows to be ignored, got %v", err)
+	}
+}
+
+func TestRunQuerySliceRowError(t *testing.T) {
+	db, mock := newMock(t)
+
+	errRow := errors.New("connection lost")
+	rows := sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana").AddRow("2", "Luis").RowError(1, errRow)
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	var usrs []user
+	if err := pgx.RunQuerySlice(context.Background(), db, `SELECT user_id, name FROM users`, &usrs); !errors.Is(err, errRow) {
+		t.Fatalf("expected the row error, got %v", err)
+	}
+
+	if usrs != nil {
+		t.Fatalf("expected no partial result, got %v", usrs)
+	}
+}
+
+func TestRunBatchInsert(t *testing.T) {
+	const q = `INSERT INTO users (user_id, name) VALUES (:user_id, :name)`
+
+	rows := []user{{ID: "1", Name: "Ana"}, {ID: "2", Name: "Luis"}, {ID: "3", Name: "Eva"}, {ID: "4", Name: "Juan"}, {ID: "5", Name: "Sara"}}
+
+	tests := []struct {
+		name     string
+		failAt   int
+		inserted int64
+	}{
+		{name: "all batches", inserted: 5},
+		{name: "second batch fails", failAt: 2, inserted: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMock(t)
+
+			// Batches of 2, 2 and 1 rows, each with its own statement.
+			batches := [][]user{rows[0:2], rows[2:4], rows[4:5]}
+			for i, batch := range batches {
+				args := make([]driver.Value, 0, 2*len(batch))
+				for _, row := range batch {
+					args = append(args, row.ID, row.Name)
+				}
+
+				exp := mock.ExpectExec(`INSERT INTO users`).WithArgs(args...)
+				if i+1 == tt.failAt {
+					exp.WillReturnError(errors.New("connection lost"))
+					break
+				}
+				exp.WillReturnResult(sqlmock.NewResult(0, int64(len(batch))))
+			}
+
+			n, err := pgx.RunBatchInsert(context.Background(), db, q, rows, 2)
+			if (err != nil) != (tt.failAt != 0) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if n != tt.inserted {
+				t.Fatalf("expected %d rows inserted, got %d", tt.inserted, n)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRunBatchInsertTimeoutPerBatch(t *testing.T) {
+	defer func(timeout time.Duration) { pgx.DefaultQueryTimeout = timeout }(pgx.DefaultQueryTimeout)
+	pgx.DefaultQueryTimeout = 50 * time.Millisecond
+
+	const q = `INSERT INTO users (user_id, name) VALUES (:user_id, :name)`
+
+	db, mock := newMock(t)
+
+	rows := []user{{ID: "1", Name: "Ana"}, {ID: "2", Name: "Luis"}}
+	for range rows {
+		mock.ExpectExec(`INSERT INTO users`).WillDelayFor(30 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	n, err := pgx.RunBatchInsert(context.Background(), db, q, rows, 1)
+	if err != nil {
+		t.Fatalf("expected every batch to get its own timeout, got %v", err)
+	}
+
+	if n != 2 {
+		t.Fatalf("expected 2 rows inserted, got %d", n)
+	}
+}
@@ -0,0 +1,112 @@
+package pgx
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// replicaCheckTimeout bounds the status check of every replica.
+const replicaCheckTimeout = 2 * time.Second
+
+// DB sends the writes to the primary database and spreads the reads across
+// its read replicas, round-robin. The replicas are considered healthy until
+// CheckReplicas says otherwise, and the reads fall back to the primary when
+// none is healthy.
+type DB struct {
+	primary  *sqlx.DB
+	replicas []*sqlx.DB
+	healthy  []atomic.Bool
+	next     atomic.Uint64
+}
+
+// NewDB constructs a DB for the primary database and its read replicas.
+func NewDB(primary *sqlx.DB, replicas ...*sqlx.DB) *DB {
+	db := DB{
+		primary:  primary,
+		replicas: replicas,
+		healthy:  make([]atomic.Bool, len(replicas)),
+	}
+
+	for i := range db.healthy {
+		db.healthy[i].Store(true)
+	}
+
+	return &db
+}
+
+// Primary returns the primary database.
+func (db *DB) Primary() *sqlx.DB {
+	return db.primary
+}
+
+// Writer returns the database the writes must be sent to, the primary.
+func (db *DB) Writer() sqlx.ExtContext {
+	return db.primary
+}
+
+// Reader returns the next healthy replica, or the primary when there is
+// none.
+func (db *DB) Reader() sqlx.ExtContext {
+	return db.reader()
+}
+
+// reader returns the next healthy replica, or the primary when there is
+// none.
+func (db *DB) reader() *sqlx.DB {
+	n := uint64(len(db.replicas))
+	if n == 0 {
+		return db.primary
+	}
+
+	start := db.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		idx := (start + i) % n
+		if db.healthy[idx].Load() {
+			return db.replicas[idx]
+		}
+	}
+
+	return db.primary
+}
+
+// CheckReplicas pings every replica and runs the round trip query of
+// StatusCheck, without its retries, to update their health. It returns the
+// number of healthy replicas. Call it periodically so the reads stop going
+// to a failing replica.
+func (db *DB) CheckReplicas(ctx context.Context) int {
+	var healthy int
+	for i, replica := range db.replicas {
+		cctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+		ok := replica.PingContext(cctx) == nil && roundTrip(cctx, replica) == nil
+		cancel()
+
+		db.healthy[i].Store(ok)
+		if ok {
+			healthy++
+		}
+	}
+
+	return healthy
+}
+
+// Start runs CheckReplicas every interval in a goroutine until the context
+// is canceled, so the reads stop going to a failing replica and come back to
+// it once it recovers.
+func (db *DB) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				db.CheckReplicas(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,122 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/jmoiron/sqlx"
+)
+
+// newPingMock returns a database backed by sqlmock that reports the pings.
+func newPingMock(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("creating sqlmock: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return sqlx.NewDb(db, "pgx"), mock
+}
+
+func TestDBReaderRoundRobin(t *testing.T) {
+	primary, _ := newMock(t)
+	r1, _ := newMock(t)
+	r2, _ := newMock(t)
+
+	db := pgx.NewDB(primary, r1, r2)
+
+	if db.Writer() != primary {
+		t.Fatal("expected the writes to go to the primary")
+	}
+
+	first, second, third := db.Reader(), db.Reader(), db.Reader()
+	if first == second || first != third || (first != r1 && first != r2) {
+		t.Fatal("expected the reads to alternate between the replicas")
+	}
+
+	if pgx.NewDB(primary).Reader() != primary {
+		t.Fatal("expected the reads to go to the primary without replicas")
+	}
+}
+
+func TestDBCheckReplicas(t *testing.T) {
+	primary, _ := newMock(t)
+	healthy, healthyMock := newPingMock(t)
+	failing, failingMock := newPingMock(t)
+
+	healthyMock.ExpectPing()
+	healthyMock.ExpectQuery("SELECT true").WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+	failingMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	db := pgx.NewDB(primary, healthy, failing)
+
+	if n := db.CheckReplicas(context.Background()); n != 1 {
+		t.Fatalf("expected 1 healthy replica, got %d", n)
+	}
+
+	for i := 0; i < 3; i++ {
+		if db.Reader() != healthy {
+			t.Fatal("expected the reads to skip the failing replica")
+		}
+	}
+
+	healthyMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	failingMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	if n := db.CheckReplicas(context.Background()); n != 0 {
+		t.Fatalf("expected no healthy replica, got %d", n)
+	}
+
+	if db.Reader() != primary {
+		t.Fatal("expected the reads to fall back to the primary")
+	}
+}
+
+func TestDBStart(t *testing.T) {
+	primary, _ := newMock(t)
+	replica, replicaMock := newPingMock(t)
+
+	replicaMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	db := pgx.NewDB(primary, replica)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db.Start(ctx, time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for db.Reader() != primary {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the periodic check to take the failing replica out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReadBeginner(t *testing.T) {
+	primary, _ := newMock(t)
+	replica, replicaMock := newMock(t)
+
+	replicaMock.ExpectBegin()
+	replicaMock.ExpectRollback()
+
+	tx, err := pgx.NewReadBeginner(pgx.NewDB(primary, replica)).Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected the transaction to begin on the replica: %s", err)
+	}
+}
@@ -0,0 +1,25 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CurrentRUTVar is the session variable holding the RUT of the user making
+// the request, read by the row level security policies.
+const CurrentRUTVar = "app.current_rut"
+
+// WithSessionVar sets a session variable scoped to the transaction, the same
+// as SET LOCAL, so it is reset on commit or rollback. set_config is used
+// since SET doesn't accept bind parameters.
+func WithSessionVar(ctx context.Context, tx sqlx.ExtContext, name string, value string) error {
+	const q = `SELECT set_config($1, $2, true)`
+
+	if _, err := tx.ExecContext(ctx, q, name, value); err != nil {
+		return fmt.Errorf("set session var %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+)
+
+func TestRunQueryTimeout(t *testing.T) {
+	const q = `SELECT user_id, name FROM users WHERE user_id = :user_id`
+
+	args := struct {
+		ID string `db:"user_id"`
+	}{ID: "1"}
+
+	t.Run("in time", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery("SELECT").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana"))
+
+		usr, err := pgx.RunQueryTimeout[user](context.Background(), db, q, args, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if usr.Name != "Ana" {
+			t.Fatalf("expected Ana, got %+v", usr)
+		}
+	})
+
+	t.Run("too slow", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery("SELECT").WithArgs("1").WillDelayFor(200 * time.Millisecond).WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana"))
+
+		_, err := pgx.RunQueryTimeout[user](context.Background(), db, q, args, 10*time.Millisecond)
+		if !errors.Is(err, pgx.ErrQueryTimeout) {
+			t.Fatalf("expected ErrQueryTimeout, got %v", err)
+		}
+	})
+
+	t.Run("request canceled", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery("SELECT").WithArgs("1").WillDelayFor(200 * time.Millisecond).WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := pgx.RunQueryTimeout[user](ctx, db, q, args, time.Second)
+		if err == nil || errors.Is(err, pgx.ErrQueryTimeout) {
+			t.Fatalf("expected the cancellation error, got %v", err)
+		}
+	})
+}
+
+func TestDefaultQueryTimeout(t *testing.T) {
+	defaultTimeout := pgx.DefaultQueryTimeout
+	t.Cleanup(func() { pgx.DefaultQueryTimeout = defaultTimeout })
+
+	pgx.DefaultQueryTimeout = 10 * time.Millisecond
+
+	const q = `SELECT user_id, name FROM users`
+
+	t.Run("no deadline", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery("SELECT").WillDelayFor(200 * time.Millisecond).WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana"))
+
+		start := time.Now()
+
+		var usr user
+		if err := pgx.RunQuery(context.Background(), db, q, &usr); err == nil || time.Since(start) > 150*time.Millisecond {
+			t.Fatalf("expected the default timeout to be applied, got %v after %s", err, time.Since(start))
+		}
+	})
+
+	t.Run("caller deadline", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery("SELECT").WillDelayFor(50 * time.Millisecond).WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		var usr user
+		if err := pgx.RunQuery(ctx, db, q, &usr); err != nil {
+			t.Fatalf("expected the caller deadline to be kept, got %v", err)
+		}
+	})
+
+	t.Run("cud", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectExec("DELETE").WillDelayFor(200 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		start := time.Now()
+
+		if err := pgx.RunCUD(context.Background(), db, `DELETE FROM users`, struct{}{}); err == nil || time.Since(start) > 150*time.Millisecond {
+			t.Fatalf("expected the default timeout to be applied, got %v after %s", err, time.Since(start))
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package pgx
+
+import (
+	"github.com/Yeremi528/laboratorio/business/data/transaction"
+	"github.com/jmoiron/sqlx"
+)
+
+// dbBeginner implements the transaction.Beginner interface.
+type dbBeginner struct {
+	sqlxDB *sqlx.DB
+}
+
+// NewBeginner constructs a value that implements the transaction.Beginner
+// interface. The transactions it begins are *sqlx.Tx values, so session
+// variables can be set on them with WithSessionVar.
+func NewBeginner(sqlxDB *sqlx.DB) transaction.Beginner {
+	return &dbBeginner{
+		sqlxDB: sqlxDB,
+	}
+}
+
+// Begin implements the transaction.Beginner interface.
+func (db *dbBeginner) Begin() (transaction.Transaction, error) {
+	return db.sqlxDB.Beginx()
+}
+
+// readBeginner implements the transaction.Beginner interface for the routes
+// that only read.
+type readBeginner struct {
+	db *DB
+}
+
+// NewReadBeginner constructs a value that implements the
+// transaction.Beginner interface beginning the transactions on the next
+// healthy replica of db, or on its primary when there is none. Only use it
+// for transactions that don't write.
+func NewReadBeginner(db *DB) transaction.Beginner {
+	return &readBeginner{
+		db: db,
+	}
+}
+
+// Begin implements the transaction.Beginner interface.
+func (rb *readBeginner) Begin() (transaction.Transaction, error) {
+	return rb.db.reader().Beginx()
+}
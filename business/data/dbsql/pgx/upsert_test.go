@@ -0,0 +1,59 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+)
+
+func TestRunUpsertReturning(t *testing.T) {
+	const q = `
+	INSERT INTO users (user_id, name) VALUES (:user_id, :name)
+	ON CONFLICT (user_id) DO UPDATE SET name = EXCLUDED.name
+	RETURNING user_id, name, (xmax = 0) AS inserted`
+
+	tests := []struct {
+		name     string
+		inserted bool
+	}{
+		{name: "insert", inserted: true},
+		{name: "update", inserted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMock(t)
+
+			rows := sqlmock.NewRows([]string{"user_id", "name", "inserted"}).AddRow("1", "Ana", tt.inserted)
+			mock.ExpectQuery("INSERT INTO users").WithArgs("1", "Ana").WillReturnRows(rows)
+
+			var got user
+			inserted, err := pgx.RunUpsertReturning(context.Background(), db, q, user{ID: "1", Name: "Ana"}, &got)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if inserted != tt.inserted {
+				t.Fatalf("expected inserted %t, got %t", tt.inserted, inserted)
+			}
+
+			if got.ID != "1" || got.Name != "Ana" {
+				t.Fatalf("expected the returned row to be scanned, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestRunUpsertReturningMissingColumn(t *testing.T) {
+	db, mock := newMock(t)
+
+	rows := sqlmock.NewRows([]string{"user_id", "name"}).AddRow("1", "Ana")
+	mock.ExpectQuery("INSERT INTO users").WillReturnRows(rows)
+
+	var got user
+	if _, err := pgx.RunUpsertReturning(context.Background(), db, `INSERT INTO users (user_id, name) VALUES (:user_id, :name) RETURNING user_id, name`, user{ID: "1", Name: "Ana"}, &got); err == nil {
+		t.Fatal("expected an error when the inserted column is missing")
+	}
+}
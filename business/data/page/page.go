@@ -2,12 +2,18 @@
 package page
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/Yeremi528/laboratorio/foundation/validate"
 )
 
+// MaxRowsPerPage is the largest number of rows that can be requested per
+// page.
+const MaxRowsPerPage = 100
+
 // Page represents the requested page and rows per page.
 type Page struct {
 	Number      int
@@ -15,7 +21,8 @@ type Page struct {
 }
 
 // Parse parses the request for the page and rows query string. The
-// defaults are provided as well.
+// defaults are provided as well. The page must be at least 1 and the rows
+// between 1 and MaxRowsPerPage.
 func Parse(r *http.Request) (Page, error) {
 	values := r.URL.Query()
 
@@ -26,6 +33,10 @@ func Parse(r *http.Request) (Page, error) {
 		if err != nil {
 			return Page{}, validate.NewFieldsError("page", err)
 		}
+
+		if number < 1 {
+			return Page{}, validate.NewFieldsError("page", errors.New("must be at least 1"))
+		}
 	}
 
 	rowsPerPage := 10
@@ -35,6 +46,10 @@ func Parse(r *http.Request) (Page, error) {
 		if err != nil {
 			return Page{}, validate.NewFieldsError("rows", err)
 		}
+
+		if rowsPerPage < 1 || rowsPerPage > MaxRowsPerPage {
+			return Page{}, validate.NewFieldsError("rows", fmt.Errorf("must be between 1 and %d", MaxRowsPerPage))
+		}
 	}
 
 	return Page{
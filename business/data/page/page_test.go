@@ -0,0 +1,50 @@
+package page_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/data/page"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  page.Page
+		field string
+	}{
+		{name: "defaults", query: "", want: page.Page{Number: 1, RowsPerPage: 10}},
+		{name: "provided", query: "page=3&rows=50", want: page.Page{Number: 3, RowsPerPage: 50}},
+		{name: "max rows", query: "rows=100", want: page.Page{Number: 1, RowsPerPage: 100}},
+		{name: "not a number", query: "page=one", field: "page"},
+		{name: "zero page", query: "page=0", field: "page"},
+		{name: "negative page", query: "page=-2", field: "page"},
+		{name: "zero rows", query: "rows=0", field: "rows"},
+		{name: "too many rows", query: "rows=1000000", field: "rows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/users?"+tt.query, nil)
+
+			got, err := page.Parse(r)
+			if tt.field != "" {
+				fields := validate.GetFieldErrors(err)
+				if len(fields) != 1 || fields[0].Field != tt.field {
+					t.Fatalf("expected an error on %s, got %v", tt.field, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
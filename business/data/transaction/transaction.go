@@ -3,6 +3,7 @@ package transaction
 
 import (
 	"context"
+	"sync"
 )
 
 // Transaction represents a value that can commit or rollback a transaction.
@@ -22,13 +23,61 @@ type ctxKey int
 
 const trKey ctxKey = 2
 
+// txValue is the value stored in the context, the transaction along with
+// the functions to run once it commits.
+type txValue struct {
+	tx       Transaction
+	mu       sync.Mutex
+	onCommit []func()
+}
+
 // Set stores a value that can manage a transaction.
 func Set(ctx context.Context, tx Transaction) context.Context {
-	return context.WithValue(ctx, trKey, tx)
+	return context.WithValue(ctx, trKey, &txValue{tx: tx})
 }
 
 // Get retrieves the value that can manage a transaction.
 func Get(ctx context.Context) (Transaction, bool) {
-	v, ok := ctx.Value(trKey).(Transaction)
-	return v, ok
+	v, ok := ctx.Value(trKey).(*txValue)
+	if !ok {
+		return nil, false
+	}
+
+	return v.tx, true
+}
+
+// OnCommit registers fn to run once the transaction stored in the context
+// commits, like publishing the events of the changes made in it. It returns
+// false when the context has no transaction, the caller must then run fn
+// itself.
+func OnCommit(ctx context.Context, fn func()) bool {
+	v, ok := ctx.Value(trKey).(*txValue)
+	if !ok {
+		return false
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.onCommit = append(v.onCommit, fn)
+
+	return true
+}
+
+// Committed runs the functions registered with OnCommit, in order. The
+// value that manages the transaction calls it after a successful commit.
+func Committed(ctx context.Context) {
+	v, ok := ctx.Value(trKey).(*txValue)
+	if !ok {
+		return
+	}
+
+	v.mu.Lock()
+	fns := v.onCommit
+	v.onCommit = nil
+	v.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
 }
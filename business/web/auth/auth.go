@@ -0,0 +1,133 @@
+// Package auth provides authentication support using JWTs signed with RSA
+// keys selected by key id.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Set of error variables for authentication and authorization.
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("attempted action is not allowed")
+)
+
+// Claims represents the authorization claims transmitted via a JWT. The
+// subject holds the user's RUT.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// KeyLookup declares a method set of behavior for looking up private and
+// public keys for JWT use. The return could be a PEM encoded string or a JWS
+// based key.
+type KeyLookup interface {
+	PrivateKey(kid string) (*rsa.PrivateKey, error)
+	PublicKey(kid string) (*rsa.PublicKey, error)
+}
+
+// Config represents information required to initialize auth.
+type Config struct {
+	Log       *logger.Logger
+	KeyLookup KeyLookup
+	ActiveKID string
+	Issuer    string
+}
+
+// Auth is used to authenticate clients. It can generate a token for a set of
+// user claims and recreate the claims by parsing the token.
+type Auth struct {
+	log       *logger.Logger
+	keyLookup KeyLookup
+	activeKID string
+	issuer    string
+	method    jwt.SigningMethod
+	parser    *jwt.Parser
+}
+
+// New creates an Auth to support authentication/authorization. The private
+// key of the active kid must be available to sign tokens.
+func New(cfg Config) (*Auth, error) {
+	if _, err := cfg.KeyLookup.PrivateKey(cfg.ActiveKID); err != nil {
+		return nil, fmt.Errorf("active kid[%s]: %w", cfg.ActiveKID, err)
+	}
+
+	method := jwt.GetSigningMethod(jwt.SigningMethodRS256.Name)
+
+	a := Auth{
+		log:       cfg.Log,
+		keyLookup: cfg.KeyLookup,
+		activeKID: cfg.ActiveKID,
+		issuer:    cfg.Issuer,
+		method:    method,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{method.Alg()}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithExpirationRequired(),
+		),
+	}
+
+	return &a, nil
+}
+
+// GenerateToken generates a signed JWT token string representing the user
+// Claims using the active kid. The issuer is set by Auth and the issued at
+// time defaults to now.
+func (a *Auth) GenerateToken(claims Claims) (string, error) {
+	claims.Issuer = a.issuer
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(time.Now())
+	}
+
+	token := jwt.NewWithClaims(a.method, claims)
+	token.Header["kid"] = a.activeKID
+
+	privateKey, err := a.keyLookup.PrivateKey(a.activeKID)
+	if err != nil {
+		return "", fmt.Errorf("kid lookup: %w", err)
+	}
+
+	str, err := token.SignedString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("signing token: %w", err)
+	}
+
+	return str, nil
+}
+
+// Authenticate processes the token to validate the sender's token is valid.
+// The bearer token is expected in the form "Bearer <token>" and is verified
+// with the public key of the kid found in its header.
+func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, error) {
+	scheme, tokenStr, found := strings.Cut(bearerToken, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return Claims{}, fmt.Errorf("%w: expected authorization header format: Bearer <token>", ErrInvalidToken)
+	}
+
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, errors.New("missing kid in token header")
+		}
+
+		return a.keyLookup.PublicKey(kid)
+	}
+
+	var claims Claims
+	if _, err := a.parser.ParseWithClaims(tokenStr, &claims, keyFunc); err != nil {
+		a.log.Debug(ctx, "authenticate", "msg", err)
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
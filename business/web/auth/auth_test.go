@@ -0,0 +1,135 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/web/auth"
+	"github.com/Yeremi528/laboratorio/foundation/keystore"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	kid    = "s4sKIjD9kIRjxs2tulPqGLdxSfgPErRN1Mu3HxaHS3s"
+	issuer = "service project"
+)
+
+// newAuth returns an Auth whose keystore holds a fresh key for each kid.
+func newAuth(t *testing.T, issuer string, kids ...string) *auth.Auth {
+	t.Helper()
+
+	keys := make(map[string]*rsa.PrivateKey)
+	for _, kid := range kids {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("generating key: %s", err)
+		}
+		keys[kid] = privateKey
+	}
+
+	a, err := auth.New(auth.Config{
+		Log:       logger.New(io.Discard, logger.LevelError, "test", nil),
+		KeyLookup: keystore.NewMap(keys),
+		ActiveKID: kids[0],
+		Issuer:    issuer,
+	})
+	if err != nil {
+		t.Fatalf("constructing auth: %s", err)
+	}
+
+	return a
+}
+
+func newClaims(expiresIn time.Duration) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "12.345.678-5",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+		Roles: []string{"ADMIN"},
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newAuth(t, issuer, kid)
+
+	token, err := a.GenerateToken(newClaims(time.Hour))
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	claims, err := a.Authenticate(context.Background(), "Bearer "+token)
+	if err != nil {
+		t.Fatalf("authenticating: %s", err)
+	}
+
+	if claims.Subject != "12.345.678-5" || claims.Issuer != issuer || claims.IssuedAt == nil {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	if len(claims.Roles) != 1 || claims.Roles[0] != "ADMIN" {
+		t.Fatalf("expected the roles to be kept, got %v", claims.Roles)
+	}
+}
+
+func TestAuthenticateInvalid(t *testing.T) {
+	a := newAuth(t, issuer, kid)
+
+	valid, err := a.GenerateToken(newClaims(time.Hour))
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	expired, err := a.GenerateToken(newClaims(-time.Minute))
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	other, err := newAuth(t, "other issuer", kid).GenerateToken(newClaims(time.Hour))
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	unknown, err := newAuth(t, issuer, "unknown-kid").GenerateToken(newClaims(time.Hour))
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	tests := []struct {
+		name   string
+		bearer string
+	}{
+		{name: "missing scheme", bearer: valid},
+		{name: "wrong scheme", bearer: "Basic " + valid},
+		{name: "malformed", bearer: "Bearer not.a.token"},
+		{name: "expired", bearer: "Bearer " + expired},
+		{name: "other issuer", bearer: "Bearer " + other},
+		{name: "unknown kid", bearer: "Bearer " + unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := a.Authenticate(context.Background(), tt.bearer); !errors.Is(err, auth.ErrInvalidToken) {
+				t.Fatalf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewUnknownActiveKID(t *testing.T) {
+	_, err := auth.New(auth.Config{
+		Log:       logger.New(io.Discard, logger.LevelError, "test", nil),
+		KeyLookup: keystore.New(),
+		ActiveKID: kid,
+		Issuer:    issuer,
+	})
+	if !errors.Is(err, keystore.ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
@@ -10,7 +10,8 @@ import (
 // Mux registers all the debug routes from the standard library into a new mux
 // bypassing the use of the DefaultServerMux. Using the DefaultServerMux would
 // be a security risk since a dependency could inject a handler into our service
-// without us knowing it.
+// without us knowing it. The mux exposes profiling and runtime data, it must be
+// served on its own host and never be exposed publicly.
 func Mux() *http.ServeMux {
 	mux := http.NewServeMux()
 
@@ -20,6 +21,7 @@ func Mux() *http.ServeMux {
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/info", infoHandler)
 
 	return mux
 }
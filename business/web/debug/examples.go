@@ -0,0 +1,79 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Example represents a request and response pair captured for a route.
+type Example struct {
+	Method     string `json:"method"`
+	Route      string `json:"route"`
+	Request    string `json:"request,omitempty"`
+	Response   string `json:"response,omitempty"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// Examples keeps the first successful example captured for every route, to
+// be served as sample payloads for the API documentation. It must only be
+// used in development since the payloads, even masked, come from real
+// requests.
+type Examples struct {
+	mu    sync.RWMutex
+	items map[string]Example
+}
+
+// NewExamples constructs an empty set of examples.
+func NewExamples() *Examples {
+	return &Examples{
+		items: make(map[string]Example),
+	}
+}
+
+// Has reports whether an example was already captured for the route.
+func (e *Examples) Has(method string, route string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	_, exists := e.items[method+" "+route]
+	return exists
+}
+
+// Capture stores the example unless one was already captured for its route.
+func (e *Examples) Capture(ex Example) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := ex.Method + " " + ex.Route
+	if _, exists := e.items[key]; !exists {
+		e.items[key] = ex
+	}
+}
+
+// List returns the captured examples sorted by route and method.
+func (e *Examples) List() []Example {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := make([]Example, 0, len(e.items))
+	for _, ex := range e.items {
+		list = append(list, ex)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Route != list[j].Route {
+			return list[i].Route < list[j].Route
+		}
+		return list[i].Method < list[j].Method
+	})
+
+	return list
+}
+
+// ServeHTTP renders the captured examples as JSON.
+func (e *Examples) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e.List())
+}
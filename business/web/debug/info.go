@@ -0,0 +1,59 @@
+package debug
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// start holds the time the service started.
+var start = time.Now()
+
+// BuildInfo represents the build and runtime information of the service. The
+// uptime is encoded in JSON as a number of seconds.
+type BuildInfo struct {
+	Build      string        `json:"build"`
+	GoVersion  string        `json:"goVersion"`
+	GOMAXPROCS int           `json:"gomaxprocs"`
+	StartTime  time.Time     `json:"startTime"`
+	Uptime     time.Duration `json:"uptime"`
+}
+
+// Info returns the build and runtime information of the service. The build
+// is read from the "build" expvar published at startup.
+func Info() BuildInfo {
+	var build string
+	if v, ok := expvar.Get("build").(*expvar.String); ok {
+		build = v.Value()
+	}
+
+	return BuildInfo{
+		Build:      build,
+		GoVersion:  runtime.Version(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		StartTime:  start,
+		Uptime:     time.Since(start),
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface, writing the uptime in
+// seconds instead of the nanoseconds of a time.Duration.
+func (bi BuildInfo) MarshalJSON() ([]byte, error) {
+	type buildInfo BuildInfo
+
+	return json.Marshal(struct {
+		buildInfo
+		Uptime float64 `json:"uptime"`
+	}{
+		buildInfo: buildInfo(bi),
+		Uptime:    bi.Uptime.Seconds(),
+	})
+}
+
+// infoHandler renders the build and runtime information as JSON.
+func infoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Info())
+}
@@ -0,0 +1,74 @@
+package debug_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/web/debug"
+)
+
+func TestInfo(t *testing.T) {
+	first := debug.Info()
+
+	time.Sleep(5 * time.Millisecond)
+
+	second := debug.Info()
+
+	if second.Uptime <= first.Uptime {
+		t.Fatalf("expected the uptime to increase, got %s then %s", first.Uptime, second.Uptime)
+	}
+
+	if !first.StartTime.Equal(second.StartTime) {
+		t.Fatalf("expected a fixed start time, got %s then %s", first.StartTime, second.StartTime)
+	}
+
+	if first.GoVersion != runtime.Version() {
+		t.Fatalf("expected go version %s, got %s", runtime.Version(), first.GoVersion)
+	}
+
+	if first.GOMAXPROCS < 1 {
+		t.Fatalf("expected GOMAXPROCS to be set, got %d", first.GOMAXPROCS)
+	}
+}
+
+func TestInfoHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	debug.Mux().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/info", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var got map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	for _, field := range []string{"build", "goVersion", "gomaxprocs", "startTime", "uptime"} {
+		if _, exists := got[field]; !exists {
+			t.Errorf("expected field %s in %v", field, got)
+		}
+	}
+}
+
+func TestInfoUptimeSeconds(t *testing.T) {
+	data, err := json.Marshal(debug.BuildInfo{Uptime: 90 * time.Second})
+	if err != nil {
+		t.Fatalf("encoding: %s", err)
+	}
+
+	var got struct {
+		Uptime float64 `json:"uptime"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	if got.Uptime != 90 {
+		t.Fatalf("expected an uptime of 90 seconds, got %v: %s", got.Uptime, data)
+	}
+}
@@ -0,0 +1,50 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+)
+
+// logLevel is the payload of the log level handler.
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+// LogLevel returns a handler for the /debug/loglevel route. A GET returns the
+// current level of the logger and a PUT, with a body like {"level":"DEBUG"},
+// changes it without restarting the service. Meant to briefly raise the
+// verbosity while diagnosing an incident.
+func LogLevel(log *logger.Logger) http.HandlerFunc {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+
+		case http.MethodPut:
+			var ll logLevel
+			if err := json.NewDecoder(r.Body).Decode(&ll); err != nil {
+				http.Error(w, "unable to decode payload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			level, err := logger.ParseLevel(ll.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			log.SetLevel(level)
+
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(logLevel{Level: log.Level().String()})
+	}
+
+	return f
+}
@@ -0,0 +1,67 @@
+package debug_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/debug"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+)
+
+func TestLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	handler := debug.LogLevel(log)
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		statusCode int
+		level      string
+	}{
+		{name: "get", method: http.MethodGet, statusCode: http.StatusOK, level: "INFO"},
+		{name: "put", method: http.MethodPut, body: `{"level":"debug"}`, statusCode: http.StatusOK, level: "DEBUG"},
+		{name: "put unknown", method: http.MethodPut, body: `{"level":"verbose"}`, statusCode: http.StatusBadRequest},
+		{name: "post", method: http.MethodPost, statusCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(tt.method, "/debug/loglevel", strings.NewReader(tt.body)))
+
+			if w.Code != tt.statusCode {
+				t.Fatalf("expected %d, got %d: %s", tt.statusCode, w.Code, w.Body)
+			}
+
+			if tt.level == "" {
+				return
+			}
+
+			var got struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("decoding response: %s", err)
+			}
+
+			if got.Level != tt.level {
+				t.Fatalf("expected level %s, got %s", tt.level, got.Level)
+			}
+		})
+	}
+
+	child := log.With("component", "test")
+	child.Debug(context.Background(), "visible")
+
+	if !strings.Contains(buf.String(), "visible") {
+		t.Fatalf("expected the debug record once the level was raised, got %s", buf.String())
+	}
+}
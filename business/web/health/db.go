@@ -0,0 +1,32 @@
+package health
+
+import (
+	"context"
+
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/jmoiron/sqlx"
+)
+
+// DBChecker checks the database with pgx.StatusCheck.
+type DBChecker struct {
+	name string
+	db   *sqlx.DB
+}
+
+// NewDBChecker constructs a checker for the database reported as name.
+func NewDBChecker(name string, db *sqlx.DB) *DBChecker {
+	return &DBChecker{
+		name: name,
+		db:   db,
+	}
+}
+
+// Name implements the Checker interface.
+func (c *DBChecker) Name() string {
+	return c.name
+}
+
+// Check implements the Checker interface.
+func (c *DBChecker) Check(ctx context.Context) error {
+	return pgx.StatusCheck(ctx, c.db)
+}
@@ -0,0 +1,39 @@
+package health
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDraining is reported while the service is draining.
+var ErrDraining = errors.New("draining")
+
+// DrainChecker fails once the service starts draining, so the readiness
+// checks take it out of the load balancer during the shutdown grace period.
+type DrainChecker struct {
+	name     string
+	draining func() bool
+}
+
+// NewDrainChecker constructs a checker reported as name that fails while
+// draining reports true, like web.App.Draining.
+func NewDrainChecker(name string, draining func() bool) *DrainChecker {
+	return &DrainChecker{
+		name:     name,
+		draining: draining,
+	}
+}
+
+// Name implements the Checker interface.
+func (c *DrainChecker) Name() string {
+	return c.name
+}
+
+// Check implements the Checker interface.
+func (c *DrainChecker) Check(ctx context.Context) error {
+	if c.draining() {
+		return ErrDraining
+	}
+
+	return nil
+}
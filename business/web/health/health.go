@@ -0,0 +1,133 @@
+// Package health provides support for readiness checks that aggregate the
+// health of the service dependencies.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Set of status values reported by the checks.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusTimeout     = "timeout"
+)
+
+// Checker represents a dependency whose health can be checked.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// Report is the result of running the checks.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Health runs the registered checkers.
+type Health struct {
+	timeout  time.Duration
+	mu       sync.RWMutex
+	checkers []Checker
+}
+
+// New constructs a Health that gives the checkers up to timeout to answer.
+func New(timeout time.Duration, checkers ...Checker) *Health {
+	return &Health{
+		timeout:  timeout,
+		checkers: checkers,
+	}
+}
+
+// Register adds a checker to the ones run by Check.
+func (h *Health) Register(c Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checkers = append(h.checkers, c)
+}
+
+// Check runs all the checkers concurrently and reports the status of each
+// one by name. The error message is reported for the ones that fail and a
+// timeout for the ones that don't answer in time. It returns true when all
+// of them are healthy.
+func (h *Health) Check(ctx context.Context) (Report, bool) {
+	h.mu.RLock()
+	checkers := h.checkers
+	h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	// The channel is buffered so the checkers that answer after the timeout
+	// don't block.
+	results := make(chan result, len(checkers))
+	for _, c := range checkers {
+		go func(c Checker) {
+			results <- result{name: c.Name(), err: c.Check(ctx)}
+		}(c)
+	}
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]string, len(checkers)),
+	}
+
+	for _, c := range checkers {
+		report.Checks[c.Name()] = StatusTimeout
+	}
+
+	healthy := true
+
+wait:
+	for range checkers {
+		select {
+		case r := <-results:
+			report.Checks[r.name] = StatusOK
+			if r.err != nil {
+				report.Checks[r.name] = r.err.Error()
+				healthy = false
+			}
+
+		case <-ctx.Done():
+			healthy = false
+			break wait
+		}
+	}
+
+	if !healthy {
+		report.Status = StatusUnavailable
+	}
+
+	return report, healthy
+}
+
+// Handler returns an http handler answering with the report of the checks,
+// with a 200 when all of them are healthy and a 503 otherwise. It's meant
+// for the Kubernetes readiness probes.
+func (h *Health) Handler() http.HandlerFunc {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		report, healthy := h.Check(r.Context())
+
+		statusCode := http.StatusOK
+		if !healthy {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(report)
+	}
+
+	return f
+}
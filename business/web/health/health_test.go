@@ -0,0 +1,71 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/web/health"
+)
+
+type checker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checker) Name() string                    { return c.name }
+func (c checker) Check(ctx context.Context) error { return c.check(ctx) }
+
+func TestHandler(t *testing.T) {
+	ok := checker{name: "db", check: func(ctx context.Context) error { return nil }}
+	failing := checker{name: "cache", check: func(ctx context.Context) error { return errors.New("connection refused") }}
+	hanging := checker{name: "queue", check: func(ctx context.Context) error { select {} }}
+
+	tests := []struct {
+		name     string
+		checkers []health.Checker
+		status   int
+		checks   map[string]string
+	}{
+		{name: "healthy", checkers: []health.Checker{ok}, status: http.StatusOK, checks: map[string]string{"db": health.StatusOK}},
+		{name: "failing", checkers: []health.Checker{ok, failing}, status: http.StatusServiceUnavailable, checks: map[string]string{"db": health.StatusOK, "cache": "connection refused"}},
+		{name: "timeout", checkers: []health.Checker{ok, hanging}, status: http.StatusServiceUnavailable, checks: map[string]string{"db": health.StatusOK, "queue": health.StatusTimeout}},
+		{name: "serving", checkers: []health.Checker{ok, health.NewDrainChecker("api", func() bool { return false })}, status: http.StatusOK, checks: map[string]string{"db": health.StatusOK, "api": health.StatusOK}},
+		{name: "draining", checkers: []health.Checker{ok, health.NewDrainChecker("api", func() bool { return true })}, status: http.StatusServiceUnavailable, checks: map[string]string{"db": health.StatusOK, "api": "draining"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := health.New(50 * time.Millisecond)
+			for _, c := range tt.checkers {
+				h.Register(c)
+			}
+
+			w := httptest.NewRecorder()
+			h.Handler()(w, httptest.NewRequest(http.MethodGet, "/debug/readiness", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, w.Code)
+			}
+
+			var report health.Report
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("decoding: %s", err)
+			}
+
+			if len(report.Checks) != len(tt.checks) {
+				t.Fatalf("expected %d checks, got %v", len(tt.checks), report.Checks)
+			}
+
+			for name, status := range tt.checks {
+				if report.Checks[name] != status {
+					t.Fatalf("expected %s to be %q, got %q", name, status, report.Checks[name])
+				}
+			}
+		})
+	}
+}
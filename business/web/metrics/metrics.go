@@ -3,7 +3,12 @@ package metrics
 import (
 	"context"
 	"expvar"
+	"fmt"
 	"runtime"
+	"sync"
+	"time"
+
+	fmetrics "github.com/Yeremi528/laboratorio/foundation/metrics"
 )
 
 // This holds the single instance of the metrics value needed for
@@ -21,6 +26,12 @@ type metrics struct {
 	requests   *expvar.Int
 	errors     *expvar.Int
 	panics     *expvar.Int
+	inFlight   *expvar.Int
+
+	routeRequests  *expvar.Map
+	statusClasses  *expvar.Map
+	routeDurations *expvar.Map
+	durationsMu    sync.Mutex
 }
 
 // init constructs the metrics value that will be used to capture metrics.
@@ -33,6 +44,11 @@ func init() {
 		requests:   expvar.NewInt("requests"),
 		errors:     expvar.NewInt("errors"),
 		panics:     expvar.NewInt("panics"),
+		inFlight:   expvar.NewInt("inflight"),
+
+		routeRequests:  expvar.NewMap("route_requests"),
+		statusClasses:  expvar.NewMap("status_classes"),
+		routeDurations: expvar.NewMap("route_durations_seconds"),
 	}
 }
 
@@ -90,3 +106,41 @@ func AddPanics(ctx context.Context) int64 {
 
 	return 0
 }
+
+// AddInFlight adds delta to the in-flight requests gauge. It doesn't depend
+// on the context since requests can be counted before the metrics are set.
+func AddInFlight(delta int64) int64 {
+	m.inFlight.Add(delta)
+	return m.inFlight.Value()
+}
+
+// AddRouteRequest counts the request for its route and status class, like
+// 2xx, and records its duration in the histogram of the route. It doesn't
+// depend on the context since it must run outside the Errors middleware to
+// know the final status code.
+func AddRouteRequest(route string, statusCode int, duration time.Duration) {
+	m.routeRequests.Add(route, 1)
+	m.statusClasses.Add(fmt.Sprintf("%dxx", statusCode/100), 1)
+
+	routeDuration(route).Observe(duration.Seconds())
+}
+
+// routeDuration returns the duration histogram of the route, creating it on
+// the first request.
+func routeDuration(route string) *fmetrics.Histogram {
+	if h, ok := m.routeDurations.Get(route).(*fmetrics.Histogram); ok {
+		return h
+	}
+
+	m.durationsMu.Lock()
+	defer m.durationsMu.Unlock()
+
+	if h, ok := m.routeDurations.Get(route).(*fmetrics.Histogram); ok {
+		return h
+	}
+
+	h := fmetrics.NewHistogram()
+	m.routeDurations.Set(route, h)
+
+	return h
+}
@@ -0,0 +1,73 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// auditMaxBytes is the maximum size of a request body written to the audit
+// records.
+const auditMaxBytes = 16 << 10
+
+// Audit writes an audit record for every request that isn't a GET, HEAD or
+// OPTIONS with the trace ID, the RUT of the user, the method, path and
+// status code along with the request body and the response. The secret
+// fields and the sensitive values found in the RUT and both payloads are
+// masked with the masker, the default masker is used when nil. Request
+// bodies larger than auditMaxBytes aren't captured, the record only tells
+// they were left out.
+func Audit(log *logger.Logger, masker *mask.Masker) web.Middleware {
+	if masker == nil {
+		masker = mask.Default()
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return handler(ctx, w, r)
+			}
+
+			// Chunked bodies have an unknown length, so the read is capped
+			// and what was read is put back in front of the rest.
+			var body []byte
+			var omitted bool
+			if r.Body != nil && r.Body != http.NoBody {
+				if r.ContentLength > auditMaxBytes {
+					omitted = true
+				} else {
+					var err error
+					if body, err = io.ReadAll(io.LimitReader(r.Body, auditMaxBytes+1)); err != nil {
+						return err
+					}
+					r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+
+					if len(body) > auditMaxBytes {
+						body = nil
+						omitted = true
+					}
+				}
+			}
+
+			err := handler(ctx, w, r)
+
+			v := web.GetValues(ctx)
+			log.Info(ctx, "audit", "traceid", v.TraceID, "rut", masker.Text(v.RUT),
+				"method", r.Method, "path", r.URL.Path, "statuscode", v.StatusCode,
+				"request", maskPayload(masker, body), "requestomitted", omitted,
+				"response", maskPayload(masker, []byte(v.Response)))
+
+			return err
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,99 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestAudit(t *testing.T) {
+	const (
+		email    = "jane.doe@example.com"
+		password = "s3cr3t-passw0rd"
+		rut      = "12.345.678-5"
+	)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			return err
+		}
+
+		web.SetRut(ctx, rut)
+
+		return web.Respond(ctx, w, map[string]string{"email": email}, http.StatusCreated)
+	}
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	app := web.NewApp(nil, mid.Audit(log, nil))
+	app.Handle(http.MethodPost, "v1", "/users", handler)
+	app.Handle(http.MethodGet, "v1", "/users", handler)
+
+	t.Run("post", func(t *testing.T) {
+		buf.Reset()
+
+		body := `{"email":"` + email + `","password":"` + password + `"}`
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(body)))
+
+		var rec struct {
+			Msg          string `json:"msg"`
+			CustomFields struct {
+				RUT        string `json:"rut"`
+				Method     string `json:"method"`
+				Path       string `json:"path"`
+				StatusCode int    `json:"statuscode"`
+				Request    string `json:"request"`
+				Response   string `json:"response"`
+			} `json:"customFields"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+			t.Fatalf("expected an audit record, got %s: %s", buf.String(), err)
+		}
+
+		fields := rec.CustomFields
+		if fields.Method != http.MethodPost || fields.Path != "/v1/users" || fields.StatusCode != http.StatusCreated {
+			t.Fatalf("unexpected audit record %+v", fields)
+		}
+
+		if fields.Request == "" || fields.Response == "" || fields.RUT == "" {
+			t.Fatalf("expected the payloads and the RUT to be recorded, got %+v", fields)
+		}
+
+		for _, secret := range []string{email, password, rut} {
+			if strings.Contains(buf.String(), secret) {
+				t.Errorf("expected %s to be masked, got %s", secret, buf.String())
+			}
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		buf.Reset()
+
+		body := `{"name":"` + strings.Repeat("a", 32<<10) + `"}`
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(body)))
+
+		if !strings.Contains(buf.String(), `"request":"","requestomitted":true`) {
+			t.Fatalf("expected the body to be left out, got %s", buf.String())
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		buf.Reset()
+
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+
+		if buf.Len() != 0 {
+			t.Fatalf("expected no audit record, got %s", buf.String())
+		}
+	})
+}
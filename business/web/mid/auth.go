@@ -0,0 +1,66 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Yeremi528/laboratorio/business/web/auth"
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// Authenticate validates the bearer token of the Authorization header. On
+// success the raw token and the subject, the user's RUT, are stored in the
+// request values along with the roles, otherwise a 401 is returned without
+// the details of the failure.
+func Authenticate(a *auth.Auth) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			bearer := r.Header.Get("Authorization")
+
+			// The client only gets a generic message, the cause is kept
+			// in the error for the logs.
+			claims, err := a.Authenticate(ctx, bearer)
+			if err != nil {
+				return fmt.Errorf("%w: %w", response.NewError(auth.ErrUnauthorized, http.StatusUnauthorized), err)
+			}
+
+			_, token, _ := strings.Cut(bearer, " ")
+			web.SetToken(ctx, token)
+			web.SetRut(ctx, claims.Subject)
+			web.SetRoles(ctx, claims.Roles)
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// Authorize returns a 403 unless the authenticated user has at least one of
+// the roles. It must run after Authenticate, which sets the roles.
+func Authorize(roles ...string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			userRoles := web.GetValues(ctx).Roles
+
+			allowed := slices.ContainsFunc(roles, func(role string) bool {
+				return slices.Contains(userRoles, role)
+			})
+			if !allowed {
+				return response.NewError(auth.ErrForbidden, http.StatusForbidden)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
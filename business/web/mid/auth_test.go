@@ -0,0 +1,162 @@
+package mid_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/web/auth"
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/keystore"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testKID = "s4sKIjD9kIRjxs2tulPqGLdxSfgPErRN1Mu3HxaHS3s"
+
+// newAuth returns an Auth signing with a fresh key.
+func newAuth(t *testing.T) *auth.Auth {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+
+	a, err := auth.New(auth.Config{
+		Log:       logger.New(io.Discard, logger.LevelError, "test", nil),
+		KeyLookup: keystore.NewMap(map[string]*rsa.PrivateKey{testKID: privateKey}),
+		ActiveKID: testKID,
+		Issuer:    "test",
+	})
+	if err != nil {
+		t.Fatalf("constructing auth: %s", err)
+	}
+
+	return a
+}
+
+// newToken returns a token for the subject with the roles.
+func newToken(t *testing.T, a *auth.Auth, subject string, expiresIn time.Duration, roles ...string) string {
+	t.Helper()
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+		Roles: roles,
+	}
+
+	token, err := a.GenerateToken(claims)
+	if err != nil {
+		t.Fatalf("generating token: %s", err)
+	}
+
+	return token
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newAuth(t)
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	var got web.Values
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		got = *web.GetValues(ctx)
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil))
+	app.Handle(http.MethodGet, "", "/", handler, mid.Authenticate(a))
+
+	valid := newToken(t, a, rut, time.Hour)
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      int
+	}{
+		{name: "signed", authorization: "Bearer " + valid, expected: http.StatusNoContent},
+		{name: "expired", authorization: "Bearer " + newToken(t, a, rut, -time.Minute), expected: http.StatusUnauthorized},
+		{name: "missing", expected: http.StatusUnauthorized},
+		{name: "tampered", authorization: "Bearer " + valid + "x", expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = web.Values{}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d: %s", tt.expected, w.Code, w.Body)
+			}
+
+			if tt.expected != http.StatusNoContent {
+				if body := w.Body.String(); !strings.Contains(body, `"error":"unauthorized"`) {
+					t.Fatalf("expected a generic message, got %s", body)
+				}
+				return
+			}
+
+			if got.RUT != rut || got.Token != valid {
+				t.Fatalf("expected the subject and token in the values, got RUT %q and token %q", got.RUT, got.Token)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	a := newAuth(t)
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil))
+	app.Handle(http.MethodGet, "", "/admin", handler, mid.Authenticate(a), mid.Authorize("ADMIN"))
+	app.Handle(http.MethodGet, "", "/any", handler, mid.Authenticate(a), mid.Authorize("ADMIN", "USER"))
+
+	tests := []struct {
+		name     string
+		path     string
+		token    string
+		expected int
+	}{
+		{name: "admin", path: "/admin", token: newToken(t, a, rut, time.Hour, "ADMIN"), expected: http.StatusNoContent},
+		{name: "user on admin route", path: "/admin", token: newToken(t, a, rut, time.Hour, "USER"), expected: http.StatusForbidden},
+		{name: "no roles", path: "/admin", token: newToken(t, a, rut, time.Hour), expected: http.StatusForbidden},
+		{name: "user on any route", path: "/any", token: newToken(t, a, rut, time.Hour, "USER"), expected: http.StatusNoContent},
+		{name: "unauthenticated", path: "/admin", expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d: %s", tt.expected, w.Code, w.Body)
+			}
+		})
+	}
+}
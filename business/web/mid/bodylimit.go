@@ -0,0 +1,40 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// MaxBodySize rejects requests whose declared Content-Length is larger than
+// maxBytes before the body is read. The body is also capped so requests that
+// lie about their size fail once the limit is reached while reading.
+func MaxBodySize(maxBytes int64) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.ContentLength > maxBytes {
+				err := fmt.Errorf("request body too large: %d bytes, limit %d bytes", r.ContentLength, maxBytes)
+				return response.NewError(err, http.StatusRequestEntityTooLarge)
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// isMaxBytesError checks if the error was caused by reading a body capped by
+// MaxBodySize beyond its limit.
+func isMaxBytesError(err error) bool {
+	var mbe *http.MaxBytesError
+	return errors.As(err, &mbe)
+}
@@ -0,0 +1,58 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	const limit = 16
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			return err
+		}
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), mid.MaxBodySize(limit))
+	app.Handle(http.MethodPost, "", "/", handler)
+
+	tests := []struct {
+		name     string
+		body     string
+		chunked  bool
+		expected int
+	}{
+		{name: "within limit", body: "small body", expected: http.StatusNoContent},
+		{name: "declared too large", body: strings.Repeat("x", limit+1), expected: http.StatusRequestEntityTooLarge},
+		{name: "chunked too large", body: strings.Repeat("x", limit+1), chunked: true, expected: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.chunked {
+				r.ContentLength = -1
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d: %s", tt.expected, w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// BufferLogs holds the log records of the request in memory and writes them
+// together, in order, once the request completes, even when it panics. Add
+// it to the routes of very chatty handlers. The records of the middleware
+// running before it, like the request start and completion, aren't buffered.
+func BufferLogs() web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, flush := logger.WithBuffer(ctx)
+			defer flush()
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
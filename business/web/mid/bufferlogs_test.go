@@ -0,0 +1,46 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestBufferLogsFlushedOnPanic(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		log.Info(ctx, "step one")
+		log.Info(ctx, "step two")
+
+		if strings.Contains(buf.String(), "step") {
+			t.Error("expected the records to be buffered while handling the request")
+		}
+
+		panic("boom")
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), mid.Panics())
+	app.Handle(http.MethodGet, "", "/", handler, mid.BufferLogs())
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	one := strings.Index(buf.String(), `"message":"step one"`)
+	two := strings.Index(buf.String(), `"message":"step two"`)
+	if one < 0 || two < one {
+		t.Fatalf("expected the buffered records to be flushed in order, got %s", buf.String())
+	}
+}
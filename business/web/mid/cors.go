@@ -0,0 +1,67 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// Set of values sent in the response to a preflight request.
+const (
+	corsAllowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders = "Accept, Authorization, Content-Type, X-Trace-ID"
+	corsMaxAge       = "86400"
+)
+
+// CORS sets the response headers needed for cross-origin requests coming
+// from the allowed origins. An origin of "*" allows any origin, otherwise
+// the origin must match exactly and is echoed back. Preflight requests are
+// answered with a 204 without reaching the handler.
+func CORS(allowedOrigins []string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			origin := r.Header.Get("Origin")
+
+			if allowed := allowOrigin(allowedOrigins, origin); allowed != "" {
+				w.Header().Set("Access-Control-Allow-Origin", allowed)
+				if allowed != "*" {
+					w.Header().Add("Vary", "Origin")
+				}
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+
+				return web.Respond(ctx, w, nil, http.StatusNoContent)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// allowOrigin returns the value for the Access-Control-Allow-Origin header,
+// or an empty string when the origin is not allowed.
+func allowOrigin(allowedOrigins []string, origin string) string {
+	if origin == "" {
+		return ""
+	}
+
+	for _, allowed := range allowedOrigins {
+		switch allowed {
+		case "*":
+			return "*"
+		case origin:
+			return origin
+		}
+	}
+
+	return ""
+}
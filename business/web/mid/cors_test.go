@@ -0,0 +1,61 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestCORS(t *testing.T) {
+	const origin = "https://app.example.com"
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, "ok", http.StatusOK)
+	}
+
+	app := web.NewApp(nil)
+	app.EnableCORS(mid.CORS([]string{origin}))
+	app.Handle(http.MethodGet, "v1", "/users", handler)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		origin        string
+		preflight     bool
+		expected      int
+		allowedOrigin string
+	}{
+		{name: "preflight", method: http.MethodOptions, path: "/v1/users", origin: origin, preflight: true, expected: http.StatusNoContent, allowedOrigin: origin},
+		{name: "preflight other origin", method: http.MethodOptions, path: "/v1/users", origin: "https://evil.example.com", preflight: true, expected: http.StatusNoContent},
+		{name: "simple request", method: http.MethodGet, path: "/v1/users", origin: origin, expected: http.StatusOK, allowedOrigin: origin},
+		{name: "unknown path", method: http.MethodGet, path: "/nope", origin: origin, expected: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, path: "/v1/users", origin: origin, expected: http.StatusMethodNotAllowed},
+		{name: "options without preflight", method: http.MethodOptions, path: "/v1/users", origin: origin, expected: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("Origin", tt.origin)
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, w.Code)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowedOrigin {
+				t.Fatalf("expected allowed origin %q, got %q", tt.allowedOrigin, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// VerifyDigest checks the integrity of the request body against the
+// Content-MD5 header or the Digest header, supporting the MD5 and SHA-256
+// algorithms encoded as base64. Requests without any of these headers are
+// passed through. The body is restored so the handler can read it again. At
+// most web.DefaultMaxBytes are read, larger bodies are rejected with a 413.
+func VerifyDigest() web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			contentMD5 := r.Header.Get("Content-MD5")
+			digest := r.Header.Get("Digest")
+
+			if contentMD5 == "" && digest == "" {
+				return handler(ctx, w, r)
+			}
+
+			var body []byte
+			if r.Body != nil {
+				var err error
+				if body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, web.DefaultMaxBytes)); err != nil {
+					return fmt.Errorf("reading body: %w", err)
+				}
+				r.Body.Close()
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if contentMD5 != "" {
+				if err := checkDigest("MD5", contentMD5, body); err != nil {
+					return response.NewError(err, http.StatusBadRequest)
+				}
+			}
+
+			for _, d := range strings.Split(digest, ",") {
+				algo, value, found := strings.Cut(strings.TrimSpace(d), "=")
+				if !found {
+					continue
+				}
+
+				if err := checkDigest(strings.ToUpper(algo), value, body); err != nil {
+					return response.NewError(err, http.StatusBadRequest)
+				}
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// checkDigest compares the base64 encoded digest against the one computed
+// over the body. Unsupported algorithms are ignored.
+func checkDigest(algo string, value string, body []byte) error {
+	var sum []byte
+	switch algo {
+	case "MD5":
+		s := md5.Sum(body)
+		sum = s[:]
+	case "SHA-256":
+		s := sha256.Sum256(body)
+		sum = s[:]
+	default:
+		return nil
+	}
+
+	expected, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s digest: %w", algo, err)
+	}
+
+	if subtle.ConstantTimeCompare(expected, sum) != 1 {
+		return errors.New(algo + " digest mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package mid_test
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	const body = `{"name":"Ana"}`
+
+	md5Sum := md5.Sum([]byte(body))
+	sha256Sum := sha256.Sum256([]byte(body))
+	validMD5 := base64.StdEncoding.EncodeToString(md5Sum[:])
+	validSHA256 := base64.StdEncoding.EncodeToString(sha256Sum[:])
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	var read string
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		read = string(b)
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), mid.VerifyDigest())
+	app.Handle(http.MethodPost, "", "/", handler)
+
+	tests := []struct {
+		name     string
+		body     string
+		headers  map[string]string
+		expected int
+	}{
+		{name: "no digest", body: body, expected: http.StatusNoContent},
+		{name: "md5 match", body: body, headers: map[string]string{"Content-MD5": validMD5}, expected: http.StatusNoContent},
+		{name: "sha256 match", body: body, headers: map[string]string{"Digest": "sha-256=" + validSHA256}, expected: http.StatusNoContent},
+		{name: "md5 mismatch", body: `{"name":"Luis"}`, headers: map[string]string{"Content-MD5": validMD5}, expected: http.StatusBadRequest},
+		{name: "sha256 mismatch", body: `{"name":"Luis"}`, headers: map[string]string{"Digest": "sha-256=" + validSHA256}, expected: http.StatusBadRequest},
+		{name: "invalid encoding", body: body, headers: map[string]string{"Content-MD5": "not base64!"}, expected: http.StatusBadRequest},
+		{name: "too large", body: strings.Repeat("x", web.DefaultMaxBytes+1), headers: map[string]string{"Content-MD5": validMD5}, expected: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read = ""
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d: %s", tt.expected, w.Code, w.Body)
+			}
+
+			if tt.expected == http.StatusNoContent && read != tt.body {
+				t.Fatalf("expected the handler to read the body, got %q", read)
+			}
+		})
+	}
+}
@@ -6,19 +6,42 @@ import (
 
 	"github.com/Yeremi528/laboratorio/business/web/response"
 	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/mask"
 	"github.com/Yeremi528/laboratorio/foundation/validate"
 	"github.com/Yeremi528/laboratorio/foundation/web"
 )
 
+// validationFailed is the error reported to the client along with the fields
+// that failed validation.
+const validationFailed = "validation failed"
+
 // Errors handles errors coming out of the call chain. It detects normal
 // application errors which are used to respond to the client in a uniform way.
-// Unexpected errors (status >= 500) are logged.
-func Errors(log *logger.Logger) web.Middleware {
+// Unexpected errors (status >= 500) are logged. Sensitive values found in the
+// error messages are masked with the masker before they are logged or sent to
+// the client, the default masker is used when nil.
+// Errors returned after the response was sent, like a stream failing
+// midway, are only logged.
+func Errors(log *logger.Logger, masker *mask.Masker) web.Middleware {
+	if masker == nil {
+		masker = mask.Default()
+	}
+
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			if err := handler(ctx, w, r); err != nil {
+				err = masker.Error(err)
 				log.Error(ctx, "message", "msg", err)
 
+				// The status code can't be changed once the response is
+				// sent.
+				if web.GetValues(ctx).StatusCode != 0 {
+					if web.IsShutdown(err) {
+						return err
+					}
+					return nil
+				}
+
 				var er response.ErrorDocument
 				var status int
 
@@ -27,20 +50,38 @@ func Errors(log *logger.Logger) web.Middleware {
 					reqErr := response.GetError(err)
 
 					if validate.IsFieldErrors(reqErr.Err) {
-						fieldErrors := validate.GetFieldErrors(reqErr.Err)
 						er = response.ErrorDocument{
-							Error:  "data validation error",
-							Fields: fieldErrors.Fields(),
+							Error:  validationFailed,
+							Fields: validate.GetFieldErrors(reqErr.Err),
 						}
 						status = reqErr.Status
 						break
 					}
 
 					er = response.ErrorDocument{
-						Error: reqErr.Error(),
+						Error: masker.Text(reqErr.Error()),
 					}
 					status = reqErr.Status
 
+				case validate.IsFieldErrors(err):
+					er = response.ErrorDocument{
+						Error:  validationFailed,
+						Fields: validate.GetFieldErrors(err),
+					}
+					status = http.StatusBadRequest
+
+				case isMaxBytesError(err):
+					er = response.ErrorDocument{
+						Error: http.StatusText(http.StatusRequestEntityTooLarge),
+					}
+					status = http.StatusRequestEntityTooLarge
+
+				case web.IsRequestError(err):
+					er = response.ErrorDocument{
+						Error: err.Error(),
+					}
+					status = http.StatusBadRequest
+
 				default:
 					er = response.ErrorDocument{
 						Error: http.StatusText(http.StatusInternalServerError),
@@ -0,0 +1,170 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestErrorsUsesMasker(t *testing.T) {
+	const email = "jane.doe@example.com"
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	masker := mask.New(mask.WithMaskChar('#'))
+	expected, err := masker.Sample(mask.MaskTypeEmail, email)
+	if err != nil {
+		t.Fatalf("masking sample: %s", err)
+	}
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return response.NewError(errors.New("user "+email+" already exists"), http.StatusConflict)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, masker))
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	for name, out := range map[string]string{"body": w.Body.String(), "log": buf.String()} {
+		if strings.Contains(out, email) {
+			t.Errorf("expected the email to be masked in the %s, got %s", name, out)
+		}
+
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected the %s to be masked with the app masker as %s, got %s", name, expected, out)
+		}
+	}
+}
+
+func TestErrorsAfterStreamStarted(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.RespondStream(ctx, w, http.StatusOK, func(enc *json.Encoder) error {
+			enc.Encode(1)
+			return errors.New("connection lost")
+		})
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil))
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// A second response appended to the stream would break the array.
+	var got []any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected a single JSON array, got %s: %s", w.Body, err)
+	}
+
+	if n := strings.Count(buf.String(), "stream interrupted: connection lost"); n != 1 {
+		t.Fatalf("expected the failure to be logged once, got %d: %s", n, buf.String())
+	}
+}
+
+func TestErrorsFieldErrors(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	type newUser struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		var nu newUser
+		return web.Decode(r, &nu)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil))
+	app.Handle(http.MethodPost, "", "/", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email":"nope"}`)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var doc struct {
+		Error  string `json:"error"`
+		Fields []struct {
+			Field string `json:"field"`
+			Error string `json:"error"`
+		} `json:"fields"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	if doc.Error != "validation failed" {
+		t.Fatalf("expected the validation failed error, got %q", doc.Error)
+	}
+
+	if len(doc.Fields) != 2 || doc.Fields[0].Field != "name" || doc.Fields[1].Field != "email" || doc.Fields[1].Error == "" {
+		t.Fatalf("expected the name and email fields in order, got %+v", doc.Fields)
+	}
+}
+
+func TestErrorsDecodeLimit(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		var nu struct {
+			Name string `json:"name"`
+		}
+		if err := web.DecodeLimit(r, &nu, 32); err != nil {
+			return err
+		}
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil))
+	app.Handle(http.MethodPost, "", "/", handler)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{name: "valid", body: `{"name":"Ana"}`, expected: http.StatusNoContent},
+		{name: "empty", body: "", expected: http.StatusBadRequest},
+		{name: "too large", body: `{"name":"` + strings.Repeat("a", 64) + `"}`, expected: http.StatusRequestEntityTooLarge},
+		{name: "unknown field", body: `{"admin":true}`, expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d: %s", tt.expected, w.Code, w.Body)
+			}
+		})
+	}
+}
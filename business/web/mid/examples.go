@@ -0,0 +1,105 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/business/web/debug"
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/go-chi/chi/v5"
+)
+
+// exampleMaxBytes is the maximum size of a request body captured as example.
+const exampleMaxBytes = 64 << 10
+
+// secretFields are the fields of the payloads whose values are always
+// masked in the examples and the audit records, whatever they look like.
+var secretFields = []string{
+	"password", "passwordConfirm", "token", "accessToken", "refreshToken", "secret", "apiKey",
+}
+
+// CaptureExamples stores the first successful request and response pair of
+// every route into the examples. On top of the masking applied by web.Respond,
+// the secret fields and the sensitive values found in both payloads are
+// masked with the masker, the default masker is used when nil. Only request
+// bodies up to exampleMaxBytes are captured. It's meant for development only.
+func CaptureExamples(examples *debug.Examples, masker *mask.Masker) web.Middleware {
+	if masker == nil {
+		masker = mask.Default()
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			var route string
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+
+			if route == "" || examples.Has(r.Method, route) {
+				return handler(ctx, w, r)
+			}
+
+			// Chunked bodies have an unknown length, so the read is capped
+			// and what was read is put back in front of the rest.
+			var body []byte
+			if r.Body != nil && r.ContentLength <= exampleMaxBytes {
+				var err error
+				if body, err = io.ReadAll(io.LimitReader(r.Body, exampleMaxBytes+1)); err != nil {
+					return err
+				}
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+
+				if len(body) > exampleMaxBytes {
+					body = nil
+				}
+			}
+
+			if err := handler(ctx, w, r); err != nil {
+				return err
+			}
+
+			v := web.GetValues(ctx)
+			if v.StatusCode < http.StatusOK || v.StatusCode >= http.StatusMultipleChoices {
+				return nil
+			}
+
+			examples.Capture(debug.Example{
+				Method:     r.Method,
+				Route:      route,
+				Request:    maskPayload(masker, body),
+				Response:   maskPayload(masker, []byte(v.Response)),
+				StatusCode: v.StatusCode,
+			})
+
+			return nil
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// maskPayload masks the secret fields of a JSON payload along with the
+// sensitive values found in it. Payloads that aren't JSON objects only get
+// the sensitive values masked.
+func maskPayload(masker *mask.Masker, payload []byte) string {
+	if len(payload) == 0 {
+		return ""
+	}
+
+	if masked, err := masker.JSONBytes(payload, secretFields...); err == nil {
+		payload = masked
+	}
+
+	return masker.Text(string(payload))
+}
+
+// readCloser combines a reader with the closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
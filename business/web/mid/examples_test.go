@@ -0,0 +1,115 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/debug"
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestCaptureExamples(t *testing.T) {
+	const (
+		email    = "jane.doe@example.com"
+		password = "s3cr3t-passw0rd"
+		token    = "eyJhbGciOiJSUzI1NiJ9.payload.signature"
+	)
+
+	var read string
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		read = string(b)
+
+		resp := struct {
+			Email string `json:"email"`
+			Token string `json:"token"`
+		}{
+			Email: email,
+			Token: token,
+		}
+
+		return web.Respond(ctx, w, resp, http.StatusCreated)
+	}
+
+	examples := debug.NewExamples()
+
+	app := web.NewApp(nil, mid.CaptureExamples(examples, nil))
+	app.Handle(http.MethodPost, "v1", "/users", handler)
+
+	body := `{"email":"` + email + `","password":"` + password + `"}`
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(body)))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	if read != body {
+		t.Fatalf("expected the handler to read the whole body, got %q", read)
+	}
+
+	list := examples.List()
+	if len(list) != 1 {
+		t.Fatalf("expected one example, got %d", len(list))
+	}
+
+	ex := list[0]
+	if ex.Route != "/v1/users" || ex.Method != http.MethodPost || ex.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected example %+v", ex)
+	}
+
+	for _, secret := range []string{email, password, token} {
+		if strings.Contains(ex.Request, secret) || strings.Contains(ex.Response, secret) {
+			t.Errorf("expected %q to be masked, got request %s and response %s", secret, ex.Request, ex.Response)
+		}
+	}
+
+	if !strings.Contains(ex.Request, `"password"`) {
+		t.Errorf("expected the request fields to be kept, got %s", ex.Request)
+	}
+}
+
+func TestCaptureExamplesChunkedBody(t *testing.T) {
+	var read int
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		read = len(b)
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	examples := debug.NewExamples()
+
+	app := web.NewApp(nil, mid.CaptureExamples(examples, nil))
+	app.Handle(http.MethodPost, "", "/upload", handler)
+
+	const size = 200 << 10
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", size)))
+	r.ContentLength = -1
+	app.ServeHTTP(httptest.NewRecorder(), r)
+
+	if read != size {
+		t.Fatalf("expected the handler to read %d bytes, got %d", size, read)
+	}
+
+	list := examples.List()
+	if len(list) != 1 {
+		t.Fatalf("expected one example, got %d", len(list))
+	}
+
+	if list[0].Request != "" {
+		t.Fatalf("expected a body over the limit not to be captured, got %d bytes", len(list[0].Request))
+	}
+}
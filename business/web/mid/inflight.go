@@ -0,0 +1,46 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/Yeremi528/laboratorio/business/web/metrics"
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// ErrOverloaded is returned when a request is rejected because the maximum
+// number of requests in flight was reached.
+var ErrOverloaded = errors.New("server overloaded, try again later")
+
+// MaxInFlight sheds load by rejecting requests with a 503 once n requests are
+// being handled at the same time. The count is also published in the
+// "inflight" expvar gauge.
+func MaxInFlight(n int) web.Middleware {
+	var inFlight atomic.Int64
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if inFlight.Add(1) > int64(n) {
+				inFlight.Add(-1)
+				return response.NewError(ErrOverloaded, http.StatusServiceUnavailable)
+			}
+			metrics.AddInFlight(1)
+
+			// The counters are decremented in a defer so a panicking handler
+			// doesn't leave its slot taken.
+			defer func() {
+				inFlight.Add(-1)
+				metrics.AddInFlight(-1)
+			}()
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,89 @@
+package mid_test
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	const limit = 2
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	block := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		started <- struct{}{}
+		<-release
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	panics := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), mid.Panics(), mid.MaxInFlight(limit))
+	app.Handle(http.MethodGet, "", "/block", block)
+	app.Handle(http.MethodGet, "", "/panic", panics)
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/block", nil))
+		}()
+		<-started
+	}
+
+	if n := inFlight(t); n != limit {
+		t.Fatalf("expected %d requests in flight, got %d", limit, n)
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/block", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if n := inFlight(t); n != 0 {
+		t.Fatalf("expected no requests in flight, got %d", n)
+	}
+
+	for i := 0; i < limit+1; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	}
+
+	if n := inFlight(t); n != 0 {
+		t.Fatalf("expected no requests in flight after panics, got %d", n)
+	}
+}
+
+// inFlight reads the in-flight requests gauge published in expvar.
+func inFlight(t *testing.T) int64 {
+	t.Helper()
+
+	v, ok := expvar.Get("inflight").(*expvar.Int)
+	if !ok {
+		t.Fatal("expected the inflight expvar to be published")
+	}
+
+	return v.Value()
+}
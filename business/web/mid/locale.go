@@ -0,0 +1,81 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// Locale picks the best supported language for the request from the
+// Accept-Language header and stores it in the request values. A language
+// matches a supported one exactly, or by its base language, like es-CL
+// matching es. The default is used when nothing matches, and is also kept as
+// the fallback of web.GetLocale.
+func Locale(supported []string, def string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			web.SetDefaultLocale(ctx, def)
+			web.SetLocale(ctx, matchLocale(r.Header.Get("Accept-Language"), supported, def))
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// acceptedLanguage is a language of the Accept-Language header with its
+// quality value.
+type acceptedLanguage struct {
+	tag string
+	q   float64
+}
+
+// matchLocale returns the supported language with the highest quality value
+// in the Accept-Language header.
+func matchLocale(header string, supported []string, def string) string {
+	var accepted []acceptedLanguage
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		if q > 0 {
+			accepted = append(accepted, acceptedLanguage{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].q > accepted[j].q
+	})
+
+	for _, al := range accepted {
+		if al.tag == "*" {
+			return def
+		}
+
+		base, _, _ := strings.Cut(al.tag, "-")
+		for _, s := range supported {
+			if strings.EqualFold(al.tag, s) || strings.EqualFold(base, s) {
+				return s
+			}
+		}
+	}
+
+	return def
+}
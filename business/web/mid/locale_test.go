@@ -0,0 +1,71 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestLocale(t *testing.T) {
+	var got string
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		got = web.GetLocale(ctx)
+		return nil
+	}
+
+	app := web.NewApp(nil)
+	app.Handle(http.MethodGet, "", "/", handler, mid.Locale([]string{"es", "en", "pt-BR"}, "es"))
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "", expected: "es"},
+		{header: "en", expected: "en"},
+		{header: "en-US,en;q=0.9", expected: "en"},
+		{header: "fr-FR,en;q=0.8,es;q=0.9", expected: "es"},
+		{header: "pt-br", expected: "pt-BR"},
+		{header: "de, fr;q=0.5", expected: "es"},
+		{header: "en;q=0, es;q=0.1", expected: "es"},
+		{header: "*", expected: "es"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.header)
+			app.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetLocaleDefault(t *testing.T) {
+	if got := web.GetLocale(context.Background()); got != web.DefaultLocale {
+		t.Fatalf("expected %s, got %s", web.DefaultLocale, got)
+	}
+}
+
+func TestGetLocaleMiddlewareDefault(t *testing.T) {
+	var got string
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		web.SetLocale(ctx, "")
+		got = web.GetLocale(ctx)
+		return nil
+	}
+
+	app := web.NewApp(nil)
+	app.Handle(http.MethodGet, "", "/", handler, mid.Locale([]string{"es", "en"}, "en"))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got != "en" {
+		t.Fatalf("expected the default of the middleware, got %s", got)
+	}
+}
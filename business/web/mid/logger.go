@@ -10,10 +10,15 @@ import (
 	"github.com/Yeremi528/laboratorio/foundation/web"
 )
 
+// Logger writes information about the request to the logs and binds a
+// logger for the request to the context so handlers and cores can retrieve
+// it. The request logger carries the method and path of the request on top
+// of the trace ID added by the required fields.
 func Logger(log *logger.Logger) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			v := web.GetValues(ctx)
+			ctx = web.WithLogger(ctx, log.With("method", r.Method, "path", r.URL.Path))
 
 			path := r.URL.Path
 			if r.URL.RawQuery != "" {
@@ -25,8 +30,11 @@ func Logger(log *logger.Logger) web.Middleware {
 
 			err := handler(ctx, w, r)
 
+			took := time.Since(v.Now)
+
 			log.Info(ctx, "request completed", "method", r.Method, "path", path,
-				"remoteaddr", r.RemoteAddr, "statuscode", v.StatusCode, "since", time.Since(v.Now))
+				"remoteaddr", r.RemoteAddr, "statuscode", v.StatusCode, "since", took,
+				"durationMs", float64(took.Microseconds())/1000)
 
 			return err
 		}
@@ -0,0 +1,83 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestLoggerBindsRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", web.TraceFields)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		web.LoggerFromContext(ctx).Info(ctx, "from handler")
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Logger(log))
+	app.Handle(http.MethodGet, "v1", "/users", handler)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+	r.Header.Set(web.TraceIDHeader, "trace-0001")
+	app.ServeHTTP(httptest.NewRecorder(), r)
+
+	rec := findRecord(t, &buf, "from handler")
+
+	for key, want := range map[string]string{"method": http.MethodGet, "path": "/v1/users", "traceID": "trace-0001"} {
+		if got := rec[key]; got != want {
+			t.Errorf("expected %s to be %q, got %v", key, want, got)
+		}
+	}
+}
+
+func TestLoggerDurationMs(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Logger(log))
+	app.Handle(http.MethodGet, "", "/", handler)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	rec := findRecord(t, &buf, "request completed")
+
+	fields, ok := rec["customFields"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected customFields, got %v", rec)
+	}
+
+	if _, ok := fields["durationMs"].(float64); !ok {
+		t.Fatalf("expected durationMs to be a number, got %T", fields["durationMs"])
+	}
+}
+
+// findRecord returns the JSON log record with the message.
+func findRecord(t *testing.T, buf *bytes.Buffer, message string) map[string]any {
+	t.Helper()
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decoding log record %q: %s", line, err)
+		}
+
+		if rec["message"] == message {
+			return rec
+		}
+	}
+
+	t.Fatalf("no record with message %q in %s", message, buf.String())
+	return nil
+}
@@ -3,9 +3,11 @@ package mid
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/Yeremi528/laboratorio/business/web/metrics"
 	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/go-chi/chi/v5"
 )
 
 // Metrics updates program counters.
@@ -33,3 +35,29 @@ func Metrics() web.Middleware {
 
 	return m
 }
+
+// RouteMetrics records the requests count, the status class and the duration
+// of every route, published in expvar. It must run before the Errors
+// middleware so the final status code is known.
+func RouteMetrics() web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+
+			err := handler(ctx, w, r)
+
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			metrics.AddRouteRequest(r.Method+" "+route, web.GetValues(ctx).StatusCode, time.Since(start))
+
+			return err
+		}
+
+		return h
+	}
+
+	return m
+}
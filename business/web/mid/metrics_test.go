@@ -0,0 +1,78 @@
+package mid_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestRouteMetrics(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		switch web.Param(r, "id") {
+		case "missing":
+			return response.NewError(errors.New("not found"), http.StatusNotFound)
+		case "broken":
+			return errors.New("boom")
+		}
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.RouteMetrics(), mid.Errors(log, nil))
+	app.Handle(http.MethodGet, "", "/metrics-test/{id}", handler)
+
+	const route = "GET /metrics-test/{id}"
+
+	before := classCounts(t)
+
+	for _, id := range []string{"1", "2", "missing", "broken"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/"+id, nil))
+	}
+
+	requests, ok := expvar.Get("route_requests").(*expvar.Map).Get(route).(*expvar.Int)
+	if !ok || requests.Value() != 4 {
+		t.Fatalf("expected 4 requests for the route, got %v", requests)
+	}
+
+	after := classCounts(t)
+	for class, n := range map[string]int64{"2xx": 2, "4xx": 1, "5xx": 1} {
+		if got := after[class] - before[class]; got != n {
+			t.Fatalf("expected %d %s responses, got %d", n, class, got)
+		}
+	}
+
+	var durations struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("route_durations_seconds").(*expvar.Map).Get(route).String()), &durations); err != nil {
+		t.Fatalf("decoding durations: %s", err)
+	}
+
+	if durations.Count != 4 {
+		t.Fatalf("expected 4 durations for the route, got %d", durations.Count)
+	}
+}
+
+// classCounts returns the responses counted by status class.
+func classCounts(t *testing.T) map[string]int64 {
+	t.Helper()
+
+	counts := make(map[string]int64)
+	expvar.Get("status_classes").(*expvar.Map).Do(func(kv expvar.KeyValue) {
+		counts[kv.Key] = kv.Value.(*expvar.Int).Value()
+	})
+
+	return counts
+}
@@ -0,0 +1,29 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// LimitQueryParams rejects requests carrying more than maxParams distinct
+// query parameters to defend against parameter pollution.
+func LimitQueryParams(maxParams int) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if n := len(r.URL.Query()); n > maxParams {
+				err := fmt.Errorf("too many query parameters: %d, limit %d", n, maxParams)
+				return response.NewError(err, http.StatusBadRequest)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,47 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestLimitQueryParams(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), mid.LimitQueryParams(2))
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{name: "no params", query: "", expected: http.StatusNoContent},
+		{name: "at limit", query: "a=1&b=2", expected: http.StatusNoContent},
+		{name: "repeated param", query: "a=1&a=2&a=3&b=4", expected: http.StatusNoContent},
+		{name: "over limit", query: "a=1&b=2&c=3", expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
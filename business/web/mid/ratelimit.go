@@ -0,0 +1,104 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/business/web/response"
+	"github.com/Yeremi528/laboratorio/foundation/cache"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"golang.org/x/time/rate"
+)
+
+// Set of values bounding the memory used by the per key limiters. Limiters
+// expire once their key has been idle for rateLimitTTL, long enough for
+// their bucket to be full again.
+const (
+	rateLimitMaxKeys = 10_000
+	rateLimitTTL     = 10 * time.Minute
+)
+
+// ErrRateLimited is returned when a request exceeds the rate limit.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// KeyFunc returns the key used to group the requests sharing a rate limit.
+type KeyFunc func(ctx context.Context, r *http.Request) string
+
+// RemoteIP groups the requests by the IP address of the client.
+func RemoteIP(ctx context.Context, r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// UserRUT groups the requests by the RUT of the authenticated user, falling
+// back to the IP address of the client for anonymous requests.
+func UserRUT(ctx context.Context, r *http.Request) string {
+	if rut := web.GetValues(ctx).RUT; rut != "" {
+		return "rut:" + rut
+	}
+
+	return RemoteIP(ctx, r)
+}
+
+// RateLimit allows up to rps requests per second, with bursts of up to burst
+// requests, for each key returned by keyFunc. Requests are keyed by RemoteIP
+// when keyFunc is nil. Requests over the limit fail with a 429 and a
+// Retry-After header.
+func RateLimit(rps int, burst int, keyFunc KeyFunc) web.Middleware {
+	if keyFunc == nil {
+		keyFunc = RemoteIP
+	}
+
+	var mu sync.Mutex
+	limiters := cache.New[string, *rate.Limiter](rateLimitMaxKeys, rateLimitTTL)
+
+	limiter := func(key string) *rate.Limiter {
+		mu.Lock()
+		defer mu.Unlock()
+
+		lim, exists := limiters.Get(key)
+		if !exists {
+			lim = rate.NewLimiter(rate.Limit(rps), burst)
+		}
+
+		// Setting the limiter on every use refreshes its expiration, so
+		// active keys keep their limiter.
+		limiters.Set(key, lim)
+
+		return lim
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			res := limiter(keyFunc(ctx, r)).Reserve()
+
+			if delay := res.Delay(); !res.OK() || delay > 0 {
+				res.Cancel()
+
+				retryAfter := 1
+				if res.OK() {
+					retryAfter = int(math.Ceil(delay.Seconds()))
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+				return response.NewError(ErrRateLimited, http.StatusTooManyRequests)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
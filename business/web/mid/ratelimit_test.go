@@ -0,0 +1,55 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestRateLimitBurst(t *testing.T) {
+	const burst = 3
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), mid.RateLimit(1, burst, nil))
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		return w
+	}
+
+	for i := 0; i < burst; i++ {
+		if w := send("10.0.0.1:1234"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected %d, got %d", i+1, http.StatusNoContent, w.Code)
+		}
+	}
+
+	w := send("10.0.0.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected %d once the burst is used, got %d", http.StatusTooManyRequests, w.Code)
+	}
+
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+
+	if w := send("10.0.0.2:1234"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected other clients to keep their own limit, got %d", w.Code)
+	}
+}
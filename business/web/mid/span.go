@@ -0,0 +1,27 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// Span sets the parent span ID of the request from the W3C traceparent
+// header so the logs written while handling the request can be correlated
+// with the caller span. It must run before the Logger middleware.
+func Span() web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if spanID := web.ParentSpanID(r); spanID != "" {
+				web.SetParentSpanID(ctx, spanID)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
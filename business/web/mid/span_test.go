@@ -0,0 +1,71 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestSpan(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name        string
+		traceparent string
+		spanID      string
+	}{
+		{name: "with span", traceparent: "00-" + traceID + "-" + spanID + "-01", spanID: spanID},
+		{name: "zero span", traceparent: "00-" + traceID + "-0000000000000000-01"},
+		{name: "no span"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LevelInfo, "test", web.TraceFields)
+
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				web.LoggerFromContext(ctx).Info(ctx, "from handler")
+				return web.Respond(ctx, w, nil, http.StatusNoContent)
+			}
+
+			app := web.NewApp(nil, mid.Span(), mid.Logger(log))
+			app.Handle(http.MethodGet, "", "/", handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.traceparent != "" {
+				r.Header.Set("traceparent", tt.traceparent)
+			}
+			app.ServeHTTP(httptest.NewRecorder(), r)
+
+			for _, message := range []string{"request started", "from handler", "request completed"} {
+				rec := findRecord(t, &buf, message)
+
+				if rec["traceID"] == nil || rec["traceID"] == "" {
+					t.Errorf("%s: expected a traceID, got %v", message, rec)
+				}
+
+				got, exists := rec["parentSpanID"]
+				switch {
+				case tt.spanID == "" && exists:
+					t.Errorf("%s: expected no parentSpanID, got %v", message, got)
+				case tt.spanID != "" && got != tt.spanID:
+					t.Errorf("%s: expected parentSpanID %s, got %v", message, tt.spanID, got)
+				}
+
+				if _, exists := rec["spanID"]; exists {
+					t.Errorf("%s: expected the caller span not to be logged as spanID, got %v", message, rec)
+				}
+			}
+		})
+	}
+}
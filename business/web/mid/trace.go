@@ -0,0 +1,58 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace starts a span for every request named by its method and route
+// pattern, like "GET /users/{id}". The span continues the trace of the
+// caller when the request carries a W3C traceparent header.
+func Trace(tracer trace.Tracer) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			ctx, span := tracer.Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+				),
+			)
+			defer span.End()
+
+			err := handler(ctx, w, r)
+
+			statusCode := web.GetValues(ctx).StatusCode
+			span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+
+			switch {
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			case statusCode >= http.StatusInternalServerError:
+				span.SetStatus(codes.Error, http.StatusText(statusCode))
+			}
+
+			return err
+		}
+
+		return h
+	}
+
+	return m
+}
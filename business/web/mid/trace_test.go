@@ -0,0 +1,68 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTrace(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if web.Param(r, "id") == "0" {
+			return errors.New("boom")
+		}
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Trace(provider.Tracer("test")), mid.Errors(log, nil))
+	app.Handle(http.MethodGet, "", "/users/{id}", handler)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	r.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	app.ServeHTTP(httptest.NewRecorder(), r)
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/0", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	if name := spans[0].Name(); name != "GET /users/{id}" {
+		t.Fatalf("expected the span named by route, got %s", name)
+	}
+
+	if got := spans[0].SpanContext().TraceID().String(); got != traceID {
+		t.Fatalf("expected the span to continue trace %s, got %s", traceID, got)
+	}
+
+	if spans[0].Status().Code == codes.Error {
+		t.Fatal("expected the successful request not to be marked as an error")
+	}
+
+	if spans[1].Status().Code != codes.Error {
+		t.Fatalf("expected the failed request to be marked as an error, got %v", spans[1].Status())
+	}
+}
@@ -7,13 +7,18 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
 	"github.com/Yeremi528/laboratorio/business/data/transaction"
 	"github.com/Yeremi528/laboratorio/foundation/logger"
 	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
 // ExecuteInTransation starts a transaction around all the storage calls within
-// the scope of the handler function.
+// the scope of the handler function. When the request carries the user's RUT
+// it is set as a session variable of the transaction, which then must be a
+// sqlx.ExtContext. The request fails rather than running the handler without
+// the variable the row level security policies depend on.
 func ExecuteInTransation(log *logger.Logger, bgn transaction.Beginner) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -38,6 +43,18 @@ func ExecuteInTransation(log *logger.Logger, bgn transaction.Beginner) web.Middl
 				}
 			}()
 
+			// Expose the user's RUT to the row level security policies.
+			if rut := web.GetValues(ctx).RUT; rut != "" {
+				ext, ok := tx.(sqlx.ExtContext)
+				if !ok {
+					return fmt.Errorf("BEGIN TRANSACTION: transaction not of type sqlx.ExtContext: %T", tx)
+				}
+
+				if err := pgx.WithSessionVar(ctx, ext, pgx.CurrentRUTVar, rut); err != nil {
+					return fmt.Errorf("BEGIN TRANSACTION: %w", err)
+				}
+			}
+
 			ctx = transaction.Set(ctx, tx)
 
 			if err := handler(ctx, w, r); err != nil {
@@ -51,6 +68,9 @@ func ExecuteInTransation(log *logger.Logger, bgn transaction.Beginner) web.Middl
 
 			hasCommited = true
 
+			// The changes are visible to others from now on.
+			transaction.Committed(ctx)
+
 			return nil
 		}
 
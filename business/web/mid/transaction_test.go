@@ -0,0 +1,154 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/Yeremi528/laboratorio/business/data/transaction"
+	"github.com/Yeremi528/laboratorio/business/web/mid"
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/jmoiron/sqlx"
+)
+
+const rut = "12.345.678-5"
+
+// setRut stores the user's RUT in the request values, as the
+// authentication does.
+func setRut(rut string) web.Middleware {
+	return func(handler web.Handler) web.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			web.SetRut(ctx, rut)
+			return handler(ctx, w, r)
+		}
+	}
+}
+
+func TestExecuteInTransationSessionVar(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("creating sqlmock: %s", err)
+	}
+	defer sqlDB.Close()
+
+	db := sqlx.NewDb(sqlDB, "pgx")
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config").WithArgs(pgx.CurrentRUTVar, rut).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT current_setting").WillReturnRows(sqlmock.NewRows([]string{"rut"}).AddRow(rut))
+	mock.ExpectCommit()
+
+	var got string
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		tx, ok := transaction.Get(ctx)
+		if !ok {
+			t.Fatal("expected a transaction in the context")
+		}
+
+		var dest struct {
+			RUT string `db:"rut"`
+		}
+		if err := pgx.RunQuery(ctx, tx.(sqlx.ExtContext), `SELECT current_setting('app.current_rut') AS rut`, &dest); err != nil {
+			return err
+		}
+		got = dest.RUT
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), setRut(rut), mid.ExecuteInTransation(log, pgx.NewBeginner(db)))
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body)
+	}
+
+	if got != rut {
+		t.Fatalf("expected the session variable to be visible within the transaction, got %q", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// plainTx is a transaction that can't run queries.
+type plainTx struct{}
+
+func (plainTx) Commit() error   { return nil }
+func (plainTx) Rollback() error { return nil }
+
+type plainBeginner struct{}
+
+func (plainBeginner) Begin() (transaction.Transaction, error) { return plainTx{}, nil }
+
+func TestExecuteInTransationFailsClosed(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		t.Fatal("expected the handler not to run without the session variable")
+		return nil
+	}
+
+	app := web.NewApp(nil, mid.Errors(log, nil), setRut(rut), mid.ExecuteInTransation(log, plainBeginner{}))
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestExecuteInTransationOnCommit(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "test", nil)
+
+	tests := []struct {
+		name      string
+		err       error
+		committed bool
+	}{
+		{name: "commit", committed: true},
+		{name: "rollback", err: errors.New("handler failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var committed bool
+
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				if !transaction.OnCommit(ctx, func() { committed = true }) {
+					t.Fatal("expected a transaction in the context")
+				}
+
+				if committed {
+					t.Fatal("expected the function to wait for the commit")
+				}
+
+				if tt.err != nil {
+					return tt.err
+				}
+				return web.Respond(ctx, w, nil, http.StatusNoContent)
+			}
+
+			app := web.NewApp(nil, mid.Errors(log, nil), mid.ExecuteInTransation(log, plainBeginner{}))
+			app.Handle(http.MethodGet, "", "/", handler)
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if committed != tt.committed {
+				t.Fatalf("expected committed to be %t, got %t", tt.committed, committed)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package response
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+)
 
 // PageDocument is the form used for API responses from query API calls.
 type PageDocument[T any] struct {
@@ -23,9 +27,11 @@ func NewPageDocument[T any](items []T, total int, page int, rowsPrePage int) Pag
 // =============================================================================
 
 // ErrorDocument is the form used for API responses from failures in the API.
+// Validation failures list the fields that failed, in the order they were
+// checked.
 type ErrorDocument struct {
-	Error  string            `json:"error"`
-	Fields map[string]string `json:"fields,omitempty"`
+	Error  string               `json:"error"`
+	Fields validate.FieldErrors `json:"fields,omitempty"`
 }
 
 // Error is used to pass an error during the request through the
@@ -47,6 +53,12 @@ func (re *Error) Error() string {
 	return re.Err.Error()
 }
 
+// Unwrap returns the wrapped error, so it can be inspected with errors.Is
+// and errors.As.
+func (re *Error) Unwrap() error {
+	return re.Err
+}
+
 // IsError checks if an error of type Error exists.
 func IsError(err error) bool {
 	var re *Error
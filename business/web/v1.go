@@ -3,10 +3,15 @@ package v1
 import (
 	"os"
 
+	"github.com/Yeremi528/laboratorio/business/data/dbsql/pgx"
+	"github.com/Yeremi528/laboratorio/business/web/auth"
+	"github.com/Yeremi528/laboratorio/business/web/debug"
 	"github.com/Yeremi528/laboratorio/business/web/mid"
 	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/mask"
 	"github.com/Yeremi528/laboratorio/foundation/web"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIMuxConfig contains all the mandatory systems required by handlers.
@@ -14,7 +19,44 @@ type APIMuxConfig struct {
 	Build    string
 	Shutdown chan os.Signal
 	Log      *logger.Logger
+	Auth     *auth.Auth
 	DB       *sqlx.DB
+	Masker   *mask.Masker
+
+	// Replicas sends the reads to the read replicas of DB when set.
+	Replicas *pgx.DB
+
+	// Tracer starts a span for every request when set.
+	Tracer trace.Tracer
+
+	// MaxInFlight limits the number of requests handled at the same time.
+	// Zero means no limit.
+	MaxInFlight int
+
+	// Audit writes an audit record for every mutating request.
+	Audit bool
+
+	// MaxBodyBytes limits the size of request bodies. Zero means no limit.
+	MaxBodyBytes int64
+
+	// MaxQueryParams limits the number of distinct query parameters of the
+	// requests. Zero means no limit.
+	MaxQueryParams int
+
+	// CORSAllowedOrigins enables CORS for the listed origins, "*" allows
+	// any origin. CORS is disabled when empty.
+	CORSAllowedOrigins []string
+
+	// Compression configures the compression of the response bodies.
+	Compression web.Compression
+
+	// Formats enables content types besides JSON for the responses, like
+	// web.ContentTypeXML, negotiated with the Accept header.
+	Formats []string
+
+	// Examples captures sample payloads for every route when set. Meant for
+	// development only.
+	Examples *debug.Examples
 }
 
 // RouteAdder defines behavior that sets the routes to bind for an instance
@@ -25,7 +67,53 @@ type RouteAdder interface {
 
 // APIMux constructs a http.Handler with all application routes defined.
 func APIMux(cfg APIMuxConfig, routeAdder RouteAdder) *web.App {
-	app := web.NewApp(cfg.Shutdown, mid.Logger(cfg.Log), mid.Errors(cfg.Log), mid.Metrics(), mid.Panics())
+	masker := cfg.Masker
+	if masker == nil {
+		masker = mask.Default()
+	}
+
+	var mw []web.Middleware
+	if cfg.Tracer != nil {
+		mw = append(mw, mid.Trace(cfg.Tracer))
+	}
+
+	mw = append(mw, mid.Span(), mid.Logger(cfg.Log), mid.RouteMetrics())
+
+	// The audit records are written outside of Errors to get the status
+	// code and response of the failed requests too.
+	if cfg.Audit {
+		mw = append(mw, mid.Audit(cfg.Log, masker))
+	}
+
+	mw = append(mw, mid.Errors(cfg.Log, masker), mid.Metrics(), mid.Panics())
+
+	if cfg.MaxInFlight > 0 {
+		mw = append(mw, mid.MaxInFlight(cfg.MaxInFlight))
+	}
+
+	if cfg.MaxBodyBytes > 0 {
+		mw = append(mw, mid.MaxBodySize(cfg.MaxBodyBytes))
+	}
+
+	if cfg.MaxQueryParams > 0 {
+		mw = append(mw, mid.LimitQueryParams(cfg.MaxQueryParams))
+	}
+
+	if cfg.Examples != nil {
+		mw = append(mw, mid.CaptureExamples(cfg.Examples, masker))
+	}
+
+	app := web.NewApp(cfg.Shutdown, mw...)
+
+	app.SetMasker(masker)
+
+	app.SetCompression(cfg.Compression)
+
+	app.SetFormats(cfg.Formats...)
+
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		app.EnableCORS(mid.CORS(cfg.CORSAllowedOrigins))
+	}
 
 	routeAdder.Add(app, cfg)
 
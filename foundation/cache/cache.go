@@ -0,0 +1,134 @@
+// Package cache provides support for a bounded in-memory cache with TTL and
+// LRU eviction.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry represents a value stored in the cache along with its expiration.
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is a bounded key/value store safe for concurrent use. Entries are
+// evicted lazily when they expire and the least recently used entry is
+// evicted when the cache is full.
+type Cache[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	ll         *list.List
+	items      map[K]*list.Element
+}
+
+// New constructs a cache that holds up to maxEntries values for the
+// specified ttl. A maxEntries of zero or less means no bound on the number of
+// entries and a ttl of zero or less means entries never expire.
+func New[K comparable, V any](maxEntries int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value stored for the key and marks it as recently used.
+// Expired entries are removed and reported as not found.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	elem, exists := c.items[key]
+	if !exists {
+		return zero, false
+	}
+
+	e := elem.Value.(*entry[K, V])
+	if c.expired(e) {
+		c.removeElement(elem)
+		return zero, false
+	}
+
+	c.ll.MoveToFront(elem)
+
+	return e.value, true
+}
+
+// Set stores the value for the key, replacing any previous value and
+// resetting its expiration. The least recently used entry is evicted when
+// the cache is full.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	if elem, exists := c.items[key]; exists {
+		e := elem.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	elem := c.ll.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	c.items[key] = elem
+
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Delete removes the value stored for the key if present.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.items[key]; exists {
+		c.removeElement(elem)
+	}
+}
+
+// Len returns the number of entries in the cache, including entries that have
+// expired but have not been evicted yet.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// Purge removes every expired entry from the cache. It can be called
+// periodically to release memory held by entries that are never read again.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.ll.Back(); elem != nil; {
+		prev := elem.Prev()
+		if c.expired(elem.Value.(*entry[K, V])) {
+			c.removeElement(elem)
+		}
+		elem = prev
+	}
+}
+
+func (c *Cache[K, V]) expired(e *entry[K, V]) bool {
+	return !e.expiresAt.IsZero() && time.Now().After(e.expiresAt)
+}
+
+func (c *Cache[K, V]) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*entry[K, V]).key)
+}
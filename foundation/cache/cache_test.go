@@ -0,0 +1,111 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/cache"
+)
+
+func TestTTLExpiry(t *testing.T) {
+	c := cache.New[string, int](10, 20*time.Millisecond)
+
+	c.Set("a", 1)
+
+	if v, exists := c.Get("a"); !exists || v != 1 {
+		t.Fatalf("expected a=1 before expiry, got %d, %t", v, exists)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, exists := c.Get("a"); exists {
+		t.Fatal("expected a to be expired")
+	}
+
+	if n := c.Len(); n != 0 {
+		t.Fatalf("expected the expired entry to be removed, got %d entries", n)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	c := cache.New[string, int](10, 20*time.Millisecond)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	time.Sleep(40 * time.Millisecond)
+	c.Set("c", 3)
+
+	c.Purge()
+
+	if n := c.Len(); n != 1 {
+		t.Fatalf("expected 1 entry after purge, got %d", n)
+	}
+
+	if _, exists := c.Get("c"); !exists {
+		t.Fatal("expected c to survive the purge")
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	c := cache.New[string, int](2, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	// Reading a makes b the least recently used entry.
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, exists := c.Get("b"); exists {
+		t.Fatal("expected b to be evicted")
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if _, exists := c.Get(key); !exists {
+			t.Fatalf("expected %s to be kept", key)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c := cache.New[string, int](0, 0)
+
+	c.Set("a", 1)
+	c.Delete("a")
+
+	if _, exists := c.Get("a"); exists {
+		t.Fatal("expected a to be deleted")
+	}
+}
+
+// TestConcurrentAccess is meant to be run with -race.
+func TestConcurrentAccess(t *testing.T) {
+	c := cache.New[int, int](50, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				key := (g*1000 + i) % 100
+				c.Set(key, i)
+				c.Get(key)
+				if i%10 == 0 {
+					c.Delete(key)
+				}
+				if i%100 == 0 {
+					c.Purge()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := c.Len(); n > 50 {
+		t.Fatalf("expected at most 50 entries, got %d", n)
+	}
+}
@@ -0,0 +1,309 @@
+// Package httpclient provides support for calling other services over HTTP
+// with retries and trace propagation.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// errBodyMaxBytes is the maximum size of a response body kept in a
+// StatusError.
+const errBodyMaxBytes = 4 << 10
+
+// StatusError is returned by the JSON helpers when the service responds with
+// a status code that isn't 2xx.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements the error interface.
+func (se *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", se.StatusCode, se.Body)
+}
+
+// Client wraps an http.Client to retry the idempotent requests that fail
+// with a connection error or a 5xx status code, waiting an exponential
+// backoff with jitter between the attempts. Every attempt is bounded by its
+// own timeout and carries the trace ID of the context.
+type Client struct {
+	client         *http.Client
+	maxRetries     int
+	baseDelay      time.Duration
+	maxDelay       time.Duration
+	attemptTimeout time.Duration
+}
+
+// Option represents a function that configures a Client.
+type Option func(c *Client)
+
+// WithHTTPClient sets the http.Client used to send the requests. The default
+// is http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithRetries sets the number of retries after the first attempt. The
+// default is 3.
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.maxRetries = max(n, 0)
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled for every
+// following retry up to maxDelay. The defaults are 100ms and 2s.
+func WithBackoff(baseDelay time.Duration, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		c.baseDelay = baseDelay
+		c.maxDelay = maxDelay
+	}
+}
+
+// WithAttemptTimeout sets the time every attempt has to complete, including
+// reading the response body. The default is 10s.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.attemptTimeout = timeout
+	}
+}
+
+// New constructs a Client.
+func New(opts ...Option) *Client {
+	c := Client{
+		client:         http.DefaultClient,
+		maxRetries:     3,
+		baseDelay:      100 * time.Millisecond,
+		maxDelay:       2 * time.Second,
+		attemptTimeout: 10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return &c
+}
+
+// Do sends the request and returns the response of the last attempt. Only
+// idempotent requests are retried, the ones with an idempotent method or an
+// Idempotency-Key header, and only when their body can be sent again. The
+// caller must close the response body.
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	// A trace ID set by the caller is kept, the traceparent is still added.
+	traceID := req.Header.Get(web.TraceIDHeader)
+	web.PropagateTraceID(ctx, req)
+	if traceID != "" {
+		req.Header.Set(web.TraceIDHeader, traceID)
+	}
+
+	retries := c.maxRetries
+	if !retryable(req) {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, req)
+
+		if attempt == retries || !shouldRetry(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		// The response of a failed attempt is discarded so the connection
+		// can be reused.
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, errBodyMaxBytes))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// attempt sends the request once, bounded by the attempt timeout. The
+// timeout keeps running while the body is read, it's released when the body
+// is closed.
+func (c *Client) attempt(ctx context.Context, req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.attemptTimeout)
+
+	req = req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("get body: %w", err)
+		}
+		req.Body = body
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// backoff returns the delay before the retry following the attempt, a random
+// value between half and all of the exponential delay.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.maxDelay
+	if attempt < 30 {
+		delay = min(c.baseDelay<<attempt, c.maxDelay)
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// GetJSON sends a GET request to the url and decodes the JSON response into
+// dest. A response that isn't 2xx is returned as a StatusError.
+func (c *Client) GetJSON(ctx context.Context, url string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	return c.doJSON(ctx, req, dest)
+}
+
+// PostJSON sends the body encoded as JSON to the url in a POST request and
+// decodes the JSON response into dest, when dest isn't nil. A response that
+// isn't 2xx is returned as a StatusError. POST requests are only retried
+// when an Idempotency-Key is provided with WithIdempotencyKey.
+func (c *Client) PostJSON(ctx context.Context, url string, body any, dest any) error {
+	return c.sendJSON(ctx, http.MethodPost, url, body, dest)
+}
+
+// PutJSON is like PostJSON but sends a PUT request, which is retried.
+func (c *Client) PutJSON(ctx context.Context, url string, body any, dest any) error {
+	return c.sendJSON(ctx, http.MethodPut, url, body, dest)
+}
+
+// sendJSON sends the body encoded as JSON with the method and decodes the
+// response into dest.
+func (c *Client) sendJSON(ctx context.Context, method string, url string, body any, dest any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if key := idempotencyKey(ctx); key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	return c.doJSON(ctx, req, dest)
+}
+
+// doJSON sends the request and decodes the JSON response into dest.
+func (c *Client) doJSON(ctx context.Context, req *http.Request, dest any) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, errBodyMaxBytes))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if dest == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// ctxKey represents the type of value for the context key.
+type ctxKey int
+
+// idempotencyKeyKey is how the idempotency key is stored in the context.
+const idempotencyKeyKey ctxKey = 1
+
+// WithIdempotencyKey returns a context that makes the JSON helpers send the
+// key in the Idempotency-Key header, which makes POST requests retryable.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey, key)
+}
+
+// idempotencyKey returns the idempotency key stored in the context.
+func idempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey).(string)
+	return key
+}
+
+// retryable checks if the request can be sent more than once.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// shouldRetry checks if the attempt failed with a connection error, a
+// timeout or a 5xx status code.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// cancelBody releases the context of an attempt when the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements the io.Closer interface.
+func (cb cancelBody) Close() error {
+	err := cb.ReadCloser.Close()
+	cb.cancel()
+	return err
+}
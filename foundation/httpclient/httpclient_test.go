@@ -0,0 +1,176 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/httpclient"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func newClient() *httpclient.Client {
+	return httpclient.New(
+		httpclient.WithBackoff(time.Millisecond, 5*time.Millisecond),
+		httpclient.WithAttemptTimeout(100*time.Millisecond),
+	)
+}
+
+func TestGetJSONRetries(t *testing.T) {
+	var attempts atomic.Int32
+	var traceID atomic.Value
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID.Store(r.Header.Get(web.TraceIDHeader))
+
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"name":"Ana"}`))
+	}))
+	defer srv.Close()
+
+	var got struct {
+		Name string `json:"name"`
+	}
+
+	ctx := context.Background()
+	if err := newClient().GetJSON(ctx, srv.URL, &got); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if attempts.Load() != 3 || got.Name != "Ana" {
+		t.Fatalf("expected the third attempt to succeed, got %d attempts and %+v", attempts.Load(), got)
+	}
+
+	if traceID.Load() != "" {
+		t.Fatalf("expected no trace id outside of a request, got %v", traceID.Load())
+	}
+}
+
+func TestDoPropagatesTraceID(t *testing.T) {
+	const traceID = "4bf92f35-77b3-4da6-a3ce-929d0e0e4736"
+
+	tests := []struct {
+		name     string
+		callerID string
+		expected string
+	}{
+		{name: "request trace id", expected: traceID},
+		{name: "caller trace id", callerID: "trace-0001", expected: "trace-0001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+			}))
+			defer srv.Close()
+
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+				if err != nil {
+					return err
+				}
+				if tt.callerID != "" {
+					req.Header.Set(web.TraceIDHeader, tt.callerID)
+				}
+
+				resp, err := newClient().Do(ctx, req)
+				if err != nil {
+					return err
+				}
+				return resp.Body.Close()
+			}
+
+			app := web.NewApp(nil)
+			app.Handle(http.MethodGet, "", "/", handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(web.TraceIDHeader, traceID)
+			app.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got := header.Get(web.TraceIDHeader); got != tt.expected {
+				t.Fatalf("expected the trace id %q, got %q", tt.expected, got)
+			}
+
+			if got := header.Get(web.TraceparentHeader); !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+				t.Fatalf("expected the traceparent of the request, got %q", got)
+			}
+		})
+	}
+}
+
+func TestPostJSONRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		attempts int32
+	}{
+		{name: "not idempotent", attempts: 1},
+		{name: "idempotency key", key: "8c7f", attempts: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer srv.Close()
+
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = httpclient.WithIdempotencyKey(ctx, tt.key)
+			}
+
+			err := newClient().PostJSON(ctx, srv.URL, map[string]string{"name": "Ana"}, nil)
+
+			var se *httpclient.StatusError
+			if !errors.As(err, &se) || se.StatusCode != http.StatusBadGateway {
+				t.Fatalf("expected a status error, got %v", err)
+			}
+
+			if attempts.Load() != tt.attempts {
+				t.Fatalf("expected %d attempts, got %d", tt.attempts, attempts.Load())
+			}
+		})
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	var attempts atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var got map[string]any
+	if err := newClient().GetJSON(context.Background(), srv.URL, &got); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if attempts.Load() != 2 {
+		t.Fatalf("expected the slow attempt to be retried, got %d attempts", attempts.Load())
+	}
+}
@@ -0,0 +1,138 @@
+// Package keystore implements the auth.KeyLookup interface. This implements
+// an in-memory keystore for JWT support.
+package keystore
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ErrKeyNotFound is returned when there is no key for the key id.
+var ErrKeyNotFound = errors.New("kid lookup failed")
+
+// KeyStore represents an in memory store implementation of the
+// KeyLookup interface for use with the auth package.
+type KeyStore struct {
+	store map[string]*rsa.PrivateKey
+}
+
+// New constructs an empty KeyStore ready for use.
+func New() *KeyStore {
+	return &KeyStore{
+		store: make(map[string]*rsa.PrivateKey),
+	}
+}
+
+// NewMap constructs a KeyStore with an initial set of keys.
+func NewMap(store map[string]*rsa.PrivateKey) *KeyStore {
+	return &KeyStore{
+		store: store,
+	}
+}
+
+// NewFS constructs a KeyStore based on a set of PEM files rooted inside of a
+// directory. The name of each PEM file will be used as the key id.
+// Example: keystore.NewFS(os.DirFS("zarf/keys/"))
+// Example: /zarf/keys/54bb2165-71e1-41a6-af3e-7da4a0e1e2c1.pem
+func NewFS(fsys fs.FS) (*KeyStore, error) {
+	ks := KeyStore{
+		store: make(map[string]*rsa.PrivateKey),
+	}
+
+	fn := func(fileName string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walkdir failure: %w", err)
+		}
+
+		if dirEntry.IsDir() {
+			return nil
+		}
+
+		if path.Ext(fileName) != ".pem" {
+			return nil
+		}
+
+		file, err := fsys.Open(fileName)
+		if err != nil {
+			return fmt.Errorf("opening key file: %w", err)
+		}
+		defer file.Close()
+
+		// limit PEM file size to 1 megabyte. This should be reasonable for
+		// almost any PEM file and prevents shenanigans like linking the file
+		// to /dev/random or something like that.
+		pemData, err := io.ReadAll(io.LimitReader(file, 1024*1024))
+		if err != nil {
+			return fmt.Errorf("reading auth private key: %w", err)
+		}
+
+		privateKey, err := parsePrivateKey(pemData)
+		if err != nil {
+			return fmt.Errorf("parsing auth private key %s: %w", fileName, err)
+		}
+
+		ks.store[strings.TrimSuffix(dirEntry.Name(), ".pem")] = privateKey
+
+		return nil
+	}
+
+	if err := fs.WalkDir(fsys, ".", fn); err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+
+	return &ks, nil
+}
+
+// PrivateKey searches the key store for a given kid and returns the private
+// key.
+func (ks *KeyStore) PrivateKey(kid string) (*rsa.PrivateKey, error) {
+	privateKey, found := ks.store[kid]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+
+	return privateKey, nil
+}
+
+// PublicKey searches the key store for a given kid and returns the public
+// key.
+func (ks *KeyStore) PublicKey(kid string) (*rsa.PublicKey, error) {
+	privateKey, err := ks.PrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &privateKey.PublicKey, nil
+}
+
+// parsePrivateKey decodes a PEM encoded RSA private key in the PKCS1 or
+// PKCS8 format.
+func parsePrivateKey(pemData []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an RSA private key")
+	}
+
+	return rsaKey, nil
+}
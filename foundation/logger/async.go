@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Overflow defines what an AsyncWriter does when its queue is full.
+type Overflow int
+
+// Set of possible overflow behaviors.
+const (
+	// OverflowBlock makes the writes wait until there is room in the queue.
+	OverflowBlock Overflow = iota
+
+	// OverflowDrop discards the records that don't fit in the queue, they
+	// are counted in Dropped.
+	OverflowDrop
+)
+
+// asyncItem is either a record to write or, when done is set, a request to
+// be notified once every previous record was written.
+type asyncItem struct {
+	data []byte
+	done chan struct{}
+}
+
+// AsyncWriter is an io.Writer that queues the records and writes them to the
+// wrapped writer from a background goroutine, so logging doesn't add the
+// latency of the write to the requests. The records are written in order.
+// Close must be called on shutdown so the queued records aren't lost.
+type AsyncWriter struct {
+	w        io.Writer
+	overflow Overflow
+	queue    chan asyncItem
+	dropped  atomic.Int64
+	stopped  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncWriter constructs an AsyncWriter queueing up to size records before
+// applying the overflow behavior.
+func NewAsyncWriter(w io.Writer, size int, overflow Overflow) *AsyncWriter {
+	aw := AsyncWriter{
+		w:        w,
+		overflow: overflow,
+		queue:    make(chan asyncItem, size),
+		stopped:  make(chan struct{}),
+	}
+
+	go func() {
+		defer close(aw.stopped)
+
+		for item := range aw.queue {
+			if item.done != nil {
+				close(item.done)
+				continue
+			}
+
+			aw.w.Write(item.data)
+		}
+	}()
+
+	return &aw
+}
+
+// Write implements the io.Writer interface queueing a copy of the record.
+// Records written after Close are written synchronously.
+func (aw *AsyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+
+	if aw.closed {
+		return aw.w.Write(p)
+	}
+
+	// The handler may reuse the buffer once Write returns.
+	item := asyncItem{data: append([]byte(nil), p...)}
+
+	if aw.overflow == OverflowDrop {
+		select {
+		case aw.queue <- item:
+		default:
+			aw.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+
+	aw.queue <- item
+	return len(p), nil
+}
+
+// Dropped returns the number of records discarded because the queue was full.
+func (aw *AsyncWriter) Dropped() int64 {
+	return aw.dropped.Load()
+}
+
+// Flush waits until the records queued so far are written.
+func (aw *AsyncWriter) Flush() {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+
+	if aw.closed {
+		return
+	}
+
+	done := make(chan struct{})
+	aw.queue <- asyncItem{done: done}
+	<-done
+}
+
+// Close writes the queued records and stops the background goroutine.
+func (aw *AsyncWriter) Close() error {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	if aw.closed {
+		return nil
+	}
+
+	aw.closed = true
+	close(aw.queue)
+	<-aw.stopped
+
+	return nil
+}
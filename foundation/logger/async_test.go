@@ -0,0 +1,93 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+)
+
+// blockingWriter holds every write until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (bw *blockingWriter) Write(p []byte) (int, error) {
+	<-bw.release
+
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	return bw.buf.Write(p)
+}
+
+func TestAsyncWriterOrder(t *testing.T) {
+	var buf bytes.Buffer
+	aw := logger.NewAsyncWriter(&buf, 8, logger.OverflowBlock)
+
+	log := logger.New(aw, logger.LevelInfo, "test", nil)
+	for i := 0; i < 100; i++ {
+		log.Info(context.Background(), fmt.Sprintf("record %d", i))
+	}
+
+	aw.Flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 100 {
+		t.Fatalf("expected 100 records, got %d", len(lines))
+	}
+
+	for i, line := range lines {
+		if !strings.Contains(line, fmt.Sprintf(`"record %d"`, i)) {
+			t.Fatalf("expected record %d in order, got %s", i, line)
+		}
+	}
+
+	if err := aw.Close(); err != nil {
+		t.Fatalf("closing: %s", err)
+	}
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+	bw := blockingWriter{release: make(chan struct{})}
+	aw := logger.NewAsyncWriter(&bw, 2, logger.OverflowDrop)
+
+	// The first record is taken by the background goroutine, which blocks
+	// writing it, and the next two fill the queue.
+	const records = 10
+	for i := 0; i < records; i++ {
+		aw.Write([]byte(fmt.Sprintf("record %d\n", i)))
+	}
+
+	close(bw.release)
+	aw.Close()
+
+	written := strings.Count(bw.buf.String(), "\n")
+	if written+int(aw.Dropped()) != records {
+		t.Fatalf("expected written %d plus dropped %d to be %d", written, aw.Dropped(), records)
+	}
+
+	if aw.Dropped() < records-3 {
+		t.Fatalf("expected at least %d records dropped, got %d", records-3, aw.Dropped())
+	}
+}
+
+func TestAsyncWriterClose(t *testing.T) {
+	var buf bytes.Buffer
+	aw := logger.NewAsyncWriter(&buf, 8, logger.OverflowBlock)
+
+	aw.Write([]byte("queued\n"))
+	aw.Close()
+
+	aw.Write([]byte("after close\n"))
+
+	if buf.String() != "queued\nafter close\n" {
+		t.Fatalf("expected the queued record to be written on close, got %q", buf.String())
+	}
+}
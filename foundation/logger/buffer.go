@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type ctxKey int
+
+const bufferKey ctxKey = 1
+
+// bufferMaxRecords is the number of records a buffer holds before writing
+// them, so a long running request can't grow it without limit.
+const bufferMaxRecords = 1000
+
+// buffer holds the records logged within a context until they are flushed.
+type buffer struct {
+	mu      sync.Mutex
+	records []bufferedRecord
+	flushed bool
+}
+
+type bufferedRecord struct {
+	handler slog.Handler
+	ctx     context.Context
+	record  slog.Record
+}
+
+// write writes the held records in order. The caller must hold the lock,
+// so the records logged meanwhile wait and keep their order.
+func (b *buffer) write() {
+	for _, br := range b.records {
+		br.handler.Handle(br.ctx, br.record)
+	}
+	b.records = nil
+}
+
+// WithBuffer returns a context whose log records are held in memory, instead
+// of being written, until the returned flush function is called. The records
+// are then written together and in order. It's meant for very chatty
+// requests, flush must be deferred so the records are written even when the
+// request panics. The records logged after the flush, like the ones of a
+// goroutine that outlives the request, are written straight away. When
+// bufferMaxRecords records are held they are written without waiting for
+// the flush.
+func WithBuffer(ctx context.Context) (context.Context, func()) {
+	b := &buffer{}
+
+	flush := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.flushed = true
+		b.write()
+	}
+
+	return context.WithValue(ctx, bufferKey, b), flush
+}
+
+// buffered adds the record to the buffer bound to the context. It returns
+// false when the context has no buffer or it was already flushed, so the
+// record has to be written by the caller.
+func buffered(ctx context.Context, handler slog.Handler, r slog.Record) bool {
+	b, ok := ctx.Value(bufferKey).(*buffer)
+	if !ok {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.flushed {
+		return false
+	}
+
+	b.records = append(b.records, bufferedRecord{handler: handler, ctx: ctx, record: r.Clone()})
+
+	if len(b.records) >= bufferMaxRecords {
+		b.write()
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+)
+
+func TestWithBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	ctx, flush := logger.WithBuffer(context.Background())
+
+	for _, msg := range []string{"first", "second", "third"} {
+		log.Info(ctx, msg)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected the records to be held until flushed, got %s", buf.String())
+	}
+
+	log.Info(context.Background(), "unbuffered")
+	if !strings.Contains(buf.String(), "unbuffered") {
+		t.Fatalf("expected records outside the buffered context to be written, got %s", buf.String())
+	}
+	buf.Reset()
+
+	flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records after the flush, got %d: %s", len(lines), buf.String())
+	}
+
+	for i, msg := range []string{"first", "second", "third"} {
+		if !strings.Contains(lines[i], `"message":"`+msg+`"`) {
+			t.Errorf("expected record %d to be %s, got %s", i, msg, lines[i])
+		}
+	}
+
+	buf.Reset()
+	flush()
+	if buf.Len() != 0 {
+		t.Fatalf("expected a second flush to write nothing, got %s", buf.String())
+	}
+}
+
+func TestWithBufferAfterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	ctx, flush := logger.WithBuffer(context.Background())
+	log.Info(ctx, "before")
+	flush()
+
+	buf.Reset()
+	log.Info(ctx, "after")
+
+	if !strings.Contains(buf.String(), `"message":"after"`) {
+		t.Fatalf("expected a record logged after the flush to be written, got %q", buf.String())
+	}
+}
+
+func TestWithBufferLimit(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	ctx, flush := logger.WithBuffer(context.Background())
+	defer flush()
+
+	for i := 0; i < 1000; i++ {
+		log.Info(ctx, "record", "n", i)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1000 {
+		t.Fatalf("expected a full buffer to be written, got %d records", len(lines))
+	}
+}
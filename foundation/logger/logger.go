@@ -6,9 +6,13 @@ import (
 	"io"
 	"log"
 	"runtime"
+	"slices"
 	"time"
 
 	"log/slog"
+
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Level represents different logging levels.
@@ -22,18 +26,44 @@ const (
 	LevelError = Level(slog.LevelError)
 )
 
+// String returns the name of the level, like INFO.
+func (l Level) String() string {
+	return slog.Level(l).String()
+}
+
+// ParseLevel parses a level name, like DEBUG or INFO, ignoring case.
+func ParseLevel(s string) (Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, err
+	}
+
+	return Level(l), nil
+}
+
 // RequiredFieldsFunc represents a function that can return required fields to be logged at root level, like the trace id from
 // the specified context.
 type RequiredFieldsFunc func(ctx context.Context) []any
 
 // Logger represents a logger for logging information.
 type Logger struct {
-	handler            slog.Handler
-	requiredFieldsFunc RequiredFieldsFunc
+	handler             slog.Handler
+	level               *slog.LevelVar
+	requiredFieldsFuncs []RequiredFieldsFunc
 }
 
 // New constructs a new log for application use.
 func New(w io.Writer, minLevel Level, serviceName string, requiredFieldsFunc RequiredFieldsFunc) *Logger {
+	return newLogger(w, minLevel, serviceName, requiredFieldsFunc, false)
+}
+
+// NewText is like New but writes the records in the human friendly
+// key=value format of slog. Meant for local development.
+func NewText(w io.Writer, minLevel Level, serviceName string, requiredFieldsFunc RequiredFieldsFunc) *Logger {
+	return newLogger(w, minLevel, serviceName, requiredFieldsFunc, true)
+}
+
+func newLogger(w io.Writer, minLevel Level, serviceName string, requiredFieldsFunc RequiredFieldsFunc, text bool) *Logger {
 	// Replace msg, level, source, and time keys to message, severity, timestamp, and file respectively.
 	f := func(groups []string, a slog.Attr) slog.Attr {
 		switch a.Key {
@@ -53,8 +83,17 @@ func New(w io.Writer, minLevel Level, serviceName string, requiredFieldsFunc Req
 		return a
 	}
 
-	// Construct the slog JSON handler for use.
-	handler := slog.Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: slog.Level(minLevel), ReplaceAttr: f}))
+	// The level is kept in a variable so it can be changed at runtime.
+	level := new(slog.LevelVar)
+	level.Set(slog.Level(minLevel))
+
+	// Construct the slog handler for use, JSON unless text was asked.
+	opts := slog.HandlerOptions{AddSource: true, Level: level, ReplaceAttr: f}
+
+	handler := slog.Handler(slog.NewJSONHandler(w, &opts))
+	if text {
+		handler = slog.NewTextHandler(w, &opts)
+	}
 
 	// Attributes to add to every log.
 	attrs := []slog.Attr{
@@ -64,17 +103,135 @@ func New(w io.Writer, minLevel Level, serviceName string, requiredFieldsFunc Req
 	// Add those attributes and capture the final handler.
 	handler = handler.WithAttrs(attrs)
 
+	var funcs []RequiredFieldsFunc
+	if requiredFieldsFunc != nil {
+		funcs = append(funcs, requiredFieldsFunc)
+	}
+
 	return &Logger{
-		handler:            handler,
-		requiredFieldsFunc: requiredFieldsFunc,
+		handler:             handler,
+		level:               level,
+		requiredFieldsFuncs: funcs,
 	}
 }
 
+// SetLevel changes the minimum level of the logger, and of every logger
+// derived from it with With, taking effect immediately.
+func (log *Logger) SetLevel(level Level) {
+	log.level.Set(slog.Level(level))
+}
+
+// Level returns the current minimum level of the logger.
+func (log *Logger) Level() Level {
+	return Level(log.level.Level())
+}
+
+// reservedKeys are the root level keys written by the logger itself. With
+// drops them so a child can not shadow the service, the trace correlation or
+// the customFields group of the call args.
+var reservedKeys = map[string]bool{
+	"message":      true,
+	"severity":     true,
+	"timestamp":    true,
+	"source":       true,
+	"serviceID":    true,
+	"trace_id":     true,
+	"span_id":      true,
+	"customFields": true,
+}
+
+// With returns a logger whose records carry the provided key/value pairs,
+// along with the required fields of the parent logger. Pairs using a key
+// reserved by the logger, like customFields, are dropped.
+func (log *Logger) With(args ...any) *Logger {
+	attrs := slices.DeleteFunc(argsToAttrs(args), func(a slog.Attr) bool {
+		return reservedKeys[a.Key]
+	})
+	if len(attrs) == 0 {
+		return log
+	}
+
+	return &Logger{
+		handler:             log.handler.WithAttrs(attrs),
+		level:               log.level,
+		requiredFieldsFuncs: log.requiredFieldsFuncs,
+	}
+}
+
+// WithRequiredFields returns a logger that also adds the fields returned by
+// the provided functions at root level, after the ones of the parent logger.
+// It allows several middleware to contribute fields, like the trace and the
+// tenant, evaluated on every write.
+func (log *Logger) WithRequiredFields(fns ...RequiredFieldsFunc) *Logger {
+	funcs := make([]RequiredFieldsFunc, 0, len(log.requiredFieldsFuncs)+len(fns))
+	funcs = append(funcs, log.requiredFieldsFuncs...)
+	for _, fn := range fns {
+		if fn != nil {
+			funcs = append(funcs, fn)
+		}
+	}
+
+	return &Logger{
+		handler:             log.handler,
+		level:               log.level,
+		requiredFieldsFuncs: funcs,
+	}
+}
+
+// argsToAttrs converts alternating key/value pairs, or slog.Attr values, into
+// a slice of attributes, the same way slog.Record.Add does.
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	return attrs
+}
+
 // NewStdLogger returns a standard library Logger that wraps the slog Logger.
 func NewStdLogger(logger *Logger, level Level) *log.Logger {
 	return slog.NewLogLogger(logger.handler, slog.Level(level))
 }
 
+// NewMaskedStdLogger is like NewStdLogger but masks the sensitive values found
+// in the messages, like emails, before they are logged. Use it for third party
+// packages logging through the standard library, like the http.Server
+// ErrorLog.
+func NewMaskedStdLogger(logger *Logger, level Level) *log.Logger {
+	return slog.NewLogLogger(maskHandler{logger.handler}, slog.Level(level))
+}
+
+// maskHandler is a slog.Handler that masks the message of the records.
+type maskHandler struct {
+	slog.Handler
+}
+
+// Handle masks the message of the record and hands it to the wrapped handler.
+func (h maskHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, mask.Text(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(a)
+		return true
+	})
+
+	return h.Handler.Handle(ctx, nr)
+}
+
+// WithAttrs implements the slog.Handler interface keeping the masking.
+func (h maskHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return maskHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements the slog.Handler interface keeping the masking.
+func (h maskHandler) WithGroup(name string) slog.Handler {
+	return maskHandler{h.Handler.WithGroup(name)}
+}
+
 // Debug logs at LevelDebug with the given context.
 func (log *Logger) Debug(ctx context.Context, msg string, args ...any) {
 	log.write(ctx, LevelDebug, 3, msg, args...)
@@ -115,6 +272,26 @@ func (log *Logger) Errorc(ctx context.Context, caller int, msg string, args ...a
 	log.write(ctx, LevelError, caller, msg, args...)
 }
 
+// Fatal logs at LevelError with the given context, marking the record with
+// a fatal attribute. It returns so the caller can trigger a graceful
+// shutdown instead of calling os.Exit, which would skip the deferred
+// cleanups. Handlers return a web.NewShutdownError and other goroutines call
+// web.App.SignalShutdown:
+//
+//	if err := integrityCheck(ctx); err != nil {
+//		log.Fatal(ctx, "integrity check", "msg", err)
+//		app.SignalShutdown()
+//		return
+//	}
+func (log *Logger) Fatal(ctx context.Context, msg string, args ...any) {
+	log.With("fatal", true).write(ctx, LevelError, 3, msg, args...)
+}
+
+// Fatalc logs the fatal condition at the specified call stack position.
+func (log *Logger) Fatalc(ctx context.Context, caller int, msg string, args ...any) {
+	log.With("fatal", true).write(ctx, LevelError, caller, msg, args...)
+}
+
 func (log *Logger) write(ctx context.Context, level Level, caller int, msg string, args ...any) {
 	slogLevel := slog.Level(level)
 
@@ -128,11 +305,23 @@ func (log *Logger) write(ctx context.Context, level Level, caller int, msg strin
 	r := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
 	//r := slog.Record{Level: slogLevel, PC: pcs[0]}
 
-	if log.requiredFieldsFunc != nil {
-		r.Add(log.requiredFieldsFunc(ctx)...)
+	// The required fields are only evaluated once the record is known to be
+	// enabled, they can be costly to build.
+	for _, fn := range log.requiredFieldsFuncs {
+		r.Add(fn(ctx)...)
+	}
+
+	// Correlate the record with the active span so the logs can be linked to
+	// the traces.
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.Add("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
 	}
 	r.AddAttrs(slog.Group("customFields", args...))
 	//r.Add(args...)
 
+	if buffered(ctx, log.handler, r) {
+		return
+	}
+
 	log.handler.Handle(ctx, r)
 }
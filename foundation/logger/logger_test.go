@@ -0,0 +1,265 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewMaskedStdLogger(t *testing.T) {
+	const email = "jane.doe@example.com"
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	std := logger.NewMaskedStdLogger(log, logger.LevelError)
+	std.Printf("http: TLS handshake error from %s", email)
+
+	out := buf.String()
+	if strings.Contains(out, email) {
+		t.Fatalf("expected the email to be masked, got %s", out)
+	}
+
+	if !strings.Contains(out, "TLS handshake error") || !strings.Contains(out, `"severity":"ERROR"`) {
+		t.Fatalf("expected the message to be logged as an error, got %s", out)
+	}
+}
+
+func TestNewStdLoggerUnmasked(t *testing.T) {
+	const email = "jane.doe@example.com"
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	logger.NewStdLogger(log, logger.LevelInfo).Printf("sent to %s", email)
+
+	if !strings.Contains(buf.String(), email) {
+		t.Fatalf("expected the plain logger to leave the message as is, got %s", buf.String())
+	}
+}
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+
+	requiredFields := func(ctx context.Context) []any {
+		return []any{"traceID", "abc"}
+	}
+
+	log := logger.New(&buf, logger.LevelInfo, "test", requiredFields)
+	child := log.With("component", "usergrp", slog.Int("userID", 7))
+
+	child.Info(context.Background(), "created", "email", "jane")
+
+	var record struct {
+		ServiceID    string         `json:"serviceID"`
+		TraceID      string         `json:"traceID"`
+		Component    string         `json:"component"`
+		UserID       int            `json:"userID"`
+		CustomFields map[string]any `json:"customFields"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decoding record: %s", err)
+	}
+
+	if record.ServiceID != "test" || record.TraceID != "abc" {
+		t.Fatalf("expected the service and required fields to be kept, got %+v", record)
+	}
+
+	if record.Component != "usergrp" || record.UserID != 7 {
+		t.Fatalf("expected the child fields, got %+v", record)
+	}
+
+	if record.CustomFields["email"] != "jane" {
+		t.Fatalf("expected the call args under customFields, got %v", record.CustomFields)
+	}
+
+	buf.Reset()
+	log.Info(context.Background(), "parent")
+
+	if strings.Contains(buf.String(), "component") {
+		t.Fatalf("expected the parent logger to be untouched, got %s", buf.String())
+	}
+}
+
+func TestWithReservedKeys(t *testing.T) {
+	var buf bytes.Buffer
+
+	requiredFields := func(ctx context.Context) []any {
+		return []any{"traceID", "abc"}
+	}
+
+	log := logger.New(&buf, logger.LevelInfo, "test", requiredFields)
+	child := log.With("customFields", "shadowed", "serviceID", "other", "component", "usergrp")
+
+	child.Info(context.Background(), "created", "email", "jane")
+
+	if n := strings.Count(buf.String(), `"customFields"`); n != 1 {
+		t.Fatalf("expected a single customFields group, got %d: %s", n, buf.String())
+	}
+
+	var record struct {
+		ServiceID    string         `json:"serviceID"`
+		TraceID      string         `json:"traceID"`
+		Component    string         `json:"component"`
+		CustomFields map[string]any `json:"customFields"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decoding record: %s", err)
+	}
+
+	if record.ServiceID != "test" || record.TraceID != "abc" || record.Component != "usergrp" {
+		t.Fatalf("expected the service, required and child fields, got %+v", record)
+	}
+
+	if record.CustomFields["email"] != "jane" {
+		t.Fatalf("expected the call args under customFields, got %v", record.CustomFields)
+	}
+
+	if log.With("customFields", "shadowed") != log {
+		t.Fatal("expected the parent logger when only reserved keys are provided")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	log.Debug(context.Background(), "hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected debug records to be dropped, got %s", buf.String())
+	}
+
+	log.SetLevel(logger.LevelDebug)
+	if log.Level() != logger.LevelDebug {
+		t.Fatalf("expected level %s, got %s", logger.LevelDebug, log.Level())
+	}
+
+	log.Debug(context.Background(), "shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Fatalf("expected the debug record once the level was lowered, got %s", buf.String())
+	}
+}
+
+func TestNewText(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewText(&buf, logger.LevelInfo, "test", nil)
+
+	log.Info(context.Background(), "user created", "userID", 7)
+
+	out := buf.String()
+	for _, s := range []string{"severity=INFO", `message="user created"`, "serviceID=test", "customFields.userID=7"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expected %q in the text record, got %s", s, out)
+		}
+	}
+}
+
+func TestFatal(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	log.Fatal(context.Background(), "integrity check", "msg", "database lost")
+
+	var record struct {
+		Severity string `json:"severity"`
+		Fatal    bool   `json:"fatal"`
+		Source   struct {
+			File string `json:"file"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decoding record: %s", err)
+	}
+
+	if record.Severity != "ERROR" || !record.Fatal {
+		t.Fatalf("expected a fatal error record, got %s", buf.String())
+	}
+
+	if !strings.HasSuffix(record.Source.File, "logger_test.go") {
+		t.Fatalf("expected the caller as source, got %s", record.Source.File)
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	var calls int
+	trace := func(ctx context.Context) []any {
+		calls++
+		return []any{"traceID", "abc"}
+	}
+	tenant := func(ctx context.Context) []any {
+		return []any{"tenant", "acme"}
+	}
+	empty := func(ctx context.Context) []any {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", trace).WithRequiredFields(tenant, empty, nil)
+
+	log.Debug(context.Background(), "disabled")
+	if calls != 0 {
+		t.Fatalf("expected the required fields not to be evaluated for disabled records, got %d calls", calls)
+	}
+
+	log.Info(context.Background(), "enabled")
+	if calls != 1 {
+		t.Fatalf("expected the required fields to be evaluated once, got %d calls", calls)
+	}
+
+	var record struct {
+		TraceID string `json:"traceID"`
+		Tenant  string `json:"tenant"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decoding record: %s", err)
+	}
+
+	if record.TraceID != "abc" || record.Tenant != "acme" {
+		t.Fatalf("expected the fields of every function, got %s", buf.String())
+	}
+}
+
+func TestSpanCorrelation(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		traceID string
+		spanID  string
+	}{
+		{name: "no span", ctx: context.Background()},
+		{name: "span", ctx: trace.ContextWithSpanContext(context.Background(), sc), traceID: sc.TraceID().String(), spanID: sc.SpanID().String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+			log.Info(tt.ctx, "traced")
+
+			var record struct {
+				TraceID string `json:"trace_id"`
+				SpanID  string `json:"span_id"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("decoding record: %s", err)
+			}
+
+			if record.TraceID != tt.traceID || record.SpanID != tt.spanID {
+				t.Fatalf("expected trace %q span %q, got %s", tt.traceID, tt.spanID, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+)
+
+// SensitiveKeys holds the attribute keys masked by default by WithRedaction
+// and the mask type applied to each of them.
+var SensitiveKeys = map[string]string{
+	"email": mask.MaskTypeEmail,
+	"phone": mask.MaskTypePhone,
+	"rut":   mask.MaskTypeRUT,
+	"token": mask.MaskTypeFixed,
+}
+
+// WithRedaction returns a logger that masks the string values of the
+// attributes whose key, ignoring case, is in keys. The keys map to the mask
+// type applied, see SensitiveKeys. Attributes nested in groups, like the
+// customFields, are masked too.
+func (log *Logger) WithRedaction(keys map[string]string) *Logger {
+	lower := make(map[string]string, len(keys))
+	for k, maskType := range keys {
+		lower[strings.ToLower(k)] = maskType
+	}
+
+	return &Logger{
+		handler:             redactHandler{Handler: log.handler, keys: lower},
+		level:               log.level,
+		requiredFieldsFuncs: log.requiredFieldsFuncs,
+	}
+}
+
+// redactHandler is a slog.Handler that masks the sensitive attributes of the
+// records.
+type redactHandler struct {
+	slog.Handler
+	keys map[string]string
+}
+
+// Handle masks the attributes of the record and hands it to the wrapped
+// handler.
+func (h redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.redact(a))
+		return true
+	})
+
+	return h.Handler.Handle(ctx, nr)
+}
+
+// WithAttrs implements the slog.Handler interface keeping the redaction.
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(a)
+	}
+
+	return redactHandler{Handler: h.Handler.WithAttrs(redacted), keys: h.keys}
+}
+
+// WithGroup implements the slog.Handler interface keeping the redaction.
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{Handler: h.Handler.WithGroup(name), keys: h.keys}
+}
+
+// redact masks the attribute when it's sensitive, walking into groups.
+func (h redactHandler) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redact(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+
+	case slog.KindString:
+		maskType, exists := h.keys[strings.ToLower(a.Key)]
+		if !exists {
+			return a
+		}
+
+		// Values that can't be masked by their mask type are masked as
+		// fixed so they never end up in plaintext.
+		masked, err := mask.Default().Sample(maskType, a.Value.String())
+		if err != nil {
+			masked, _ = mask.Default().Sample(mask.MaskTypeFixed, a.Value.String())
+		}
+		return slog.String(a.Key, masked)
+	}
+
+	return a
+}
@@ -0,0 +1,30 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+)
+
+func TestWithRedaction(t *testing.T) {
+	const email = "jane.doe@example.com"
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil).WithRedaction(logger.SensitiveKeys)
+
+	log.With("Token", "secret-token").Info(context.Background(), "user created", "email", email, "name", "Jane Doe")
+
+	out := buf.String()
+	for _, s := range []string{email, "secret-token"} {
+		if strings.Contains(out, s) {
+			t.Fatalf("expected %s to be masked, got %s", s, out)
+		}
+	}
+
+	if !strings.Contains(out, `"name":"Jane Doe"`) {
+		t.Fatalf("expected the unrelated string to be untouched, got %s", out)
+	}
+}
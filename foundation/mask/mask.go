@@ -1,22 +1,203 @@
+// Package mask provides support for masking sensitive values in structs and
+// JSON documents before they are logged or stored.
 package mask
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/showa-93/go-mask"
 )
 
+// Set of mask types registered by default.
+const (
+	MaskTypeFilled = mask.MaskTypeFilled
+	MaskTypeFixed  = mask.MaskTypeFixed
+)
+
+// defaultMasker is used by the package level functions.
+var defaultMasker = New()
+
+// Masker holds the set of mask types that can be applied through the mask
+// tag or the field names provided to its methods.
+type Masker struct {
+	masker         *mask.Masker
+	funcs          map[string]mask.MaskStringFunc
+	types          []string
+	emailKeepRatio int
+	hashKey        []byte
+}
+
+// Option represents a function that configures a Masker.
+type Option func(m *Masker)
+
+// WithMaskChar sets the character used to mask values. The default is '*'.
+func WithMaskChar(r rune) Option {
+	return func(m *Masker) {
+		m.masker.SetMaskChar(string(r))
+	}
+}
+
+// WithEmailKeepRatio sets how much of the local part of an email is kept
+// visible: one character out of every n, and at least one. The default is 3.
+func WithEmailKeepRatio(n int) Option {
+	return func(m *Masker) {
+		if n > 0 {
+			m.emailKeepRatio = n
+		}
+	}
+}
+
+// WithHashKey sets the key of the HMAC used by the hash mask type. The hash
+// mask type fails without a key, so values are never hashed with a key an
+// attacker could guess.
+func WithHashKey(key []byte) Option {
+	return func(m *Masker) {
+		m.hashKey = key
+	}
+}
+
+// New constructs a Masker with the default mask types registered.
+func New(opts ...Option) *Masker {
+	m := Masker{
+		masker:         mask.NewMasker(),
+		funcs:          make(map[string]mask.MaskStringFunc),
+		emailKeepRatio: 3,
+	}
+
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	m.register(MaskTypeFilled, m.masker.MaskFilledString)
+	m.register(MaskTypeFixed, m.masker.MaskFixedString)
+	m.register(MaskTypePhone, m.maskPhone)
+	m.register(MaskTypeRUT, m.maskRUT)
+	m.register(MaskTypeCard, m.maskCard)
+	m.register(MaskTypeEmail, m.maskEmail)
+	m.register(MaskTypeName, m.maskName)
+	m.register(MaskTypeIP, m.maskIP)
+	m.register(MaskTypeHash, m.maskHash)
+
+	return &m
+}
+
+// register adds the mask function under the given type.
+func (m *Masker) register(maskType string, fn mask.MaskStringFunc) {
+	if _, exists := m.funcs[maskType]; !exists {
+		m.types = append(m.types, maskType)
+	}
+
+	m.funcs[maskType] = fn
+	m.masker.RegisterMaskStringFunc(maskType, fn)
+}
+
+// RegisterPredicate registers a new mask type that applies the base mask
+// type only to the values for which the predicate returns true. Other values
+// are left untouched. It allows tagging generic fields, like notes, that
+// should only be masked when they contain sensitive data. Mask types are
+// matched by prefix against the tag, so the new type can't start with the
+// name of a registered type nor be a prefix of one. It must be called before
+// the masker is used.
+func (m *Masker) RegisterPredicate(maskType string, pred func(value string) bool, base string) error {
+	fn, exists := m.funcs[base]
+	if !exists {
+		return fmt.Errorf("unknown base mask type: %s", base)
+	}
+
+	for _, t := range m.types {
+		if strings.HasPrefix(maskType, t) || strings.HasPrefix(t, maskType) {
+			return fmt.Errorf("mask type %s conflicts with registered mask type %s", maskType, t)
+		}
+	}
+
+	f := func(arg, value string) (string, error) {
+		if !pred(value) {
+			return value, nil
+		}
+
+		return fn(arg, value)
+	}
+
+	m.register(maskType, f)
+
+	return nil
+}
+
+// RegisteredTypes returns the sorted list of mask types known by the masker.
+func (m *Masker) RegisteredTypes() []string {
+	types := make([]string, len(m.types))
+	copy(types, m.types)
+	sort.Strings(types)
+
+	return types
+}
+
+// Sample applies a single mask type to the input. It's useful to document
+// the behavior of every registered mask type.
+func (m *Masker) Sample(maskType, input string) (string, error) {
+	fn, exists := m.funcs[maskType]
+	if !exists {
+		return "", fmt.Errorf("unknown mask type: %s", maskType)
+	}
+
+	return fn("", input)
+}
+
+// fields returns a masker that also masks the specified field names. A new
+// masker is built when field names are provided so they don't leak between
+// calls.
+func (m *Masker) fields(params ...string) *mask.Masker {
+	if len(params) == 0 {
+		return m.masker
+	}
+
+	masker := m.clone()
+	for _, p := range params {
+		masker.RegisterMaskField(p, MaskTypeFixed)
+	}
+
+	return masker
+}
+
+// clone returns a new masker with the same mask character and mask types,
+// so field names can be registered in it without changing this one.
+func (m *Masker) clone() *mask.Masker {
+	masker := mask.NewMasker()
+	masker.SetMaskChar(m.masker.MaskChar())
+	for _, maskType := range m.types {
+		masker.RegisterMaskStringFunc(maskType, m.funcs[maskType])
+	}
+
+	return masker
+}
+
 // Struct takes a struct value and a list of field names (optional).
-// It masks the values of the specified fields in the JSON with a predefined mask.
-// The function returns the masked struct as a byte slice or an error if any.
+// It masks the values of the specified fields with a predefined mask.
+// The function returns the masked struct or an error if any.
 // We encourage you to use the mask tag for readability instead of the optional params.
-func Struct(v any, params ...string) (any, error) {
-	masker := mask.NewMasker()
-	masker.RegisterMaskStringFunc(mask.MaskTypeFilled, masker.MaskFilledString)
-	masker.RegisterMaskStringFunc(mask.MaskTypeFixed, masker.MaskFixedString)
-	for _, p := range params {
-		masker.RegisterMaskField(p, "filled")
-		masker.RegisterMaskField(p, "fixed")
+func (m *Masker) Struct(v any, params ...string) (any, error) {
+	masked, err := m.fields(params...).Mask(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return masked, nil
+}
+
+// StructWith takes a struct value and a map of field names to the mask type
+// applied to them, like "email" or "rut", on top of the mask tags. It allows
+// masking structs that can't be tagged, like the ones of third party
+// packages. The overrides only apply to this call.
+func (m *Masker) StructWith(v any, overrides map[string]string) (any, error) {
+	masker := m.clone()
+	for field, maskType := range overrides {
+		if _, exists := m.funcs[maskType]; !exists {
+			return nil, fmt.Errorf("unknown mask type %s for field %s", maskType, field)
+		}
+		masker.RegisterMaskField(field, maskType)
 	}
 
 	masked, err := masker.Mask(v)
@@ -28,19 +209,11 @@ func Struct(v any, params ...string) (any, error) {
 }
 
 // StructToByte takes a struct value and a list of field names (optional).
-// It masks the values of the specified fields in the JSON with a predefined mask.
-// The function returns the masked struct as a byte slice or an error if any.
+// It masks the values of the specified fields with a predefined mask.
+// The function returns the masked struct as a JSON byte slice or an error if any.
 // We encourage you to use the mask tag for readability instead of the optional params.
-func StructToByte(v any, params ...string) ([]byte, error) {
-	masker := mask.NewMasker()
-	masker.RegisterMaskStringFunc(mask.MaskTypeFilled, masker.MaskFilledString)
-	masker.RegisterMaskStringFunc(mask.MaskTypeFixed, masker.MaskFixedString)
-	for _, p := range params {
-		masker.RegisterMaskField(p, "filled")
-		masker.RegisterMaskField(p, "fixed")
-	}
-
-	masked, err := masker.Mask(v)
+func (m *Masker) StructToByte(v any, params ...string) ([]byte, error) {
+	masked, err := m.Struct(v, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -53,26 +226,18 @@ func StructToByte(v any, params ...string) ([]byte, error) {
 	return mv, nil
 }
 
-// MaskJSONBytes takes a JSON byte slice and a list of field names.
+// JSONBytes takes a JSON byte slice and a list of field names.
 // It masks the values of the specified fields in the JSON with a predefined mask.
 // The function returns the masked JSON byte slice or an error if any.
 // If you have the struct opt for the Struct function instead, and complement it using the mask tag.
-func JSONBytes(data []byte, params ...string) ([]byte, error) {
-	var m map[string]any
+func (m *Masker) JSONBytes(data []byte, params ...string) ([]byte, error) {
+	var v map[string]any
 
-	if err := json.Unmarshal(data, &m); err != nil {
+	if err := json.Unmarshal(data, &v); err != nil {
 		return nil, err
 	}
 
-	masker := mask.NewMasker()
-	masker.RegisterMaskStringFunc(mask.MaskTypeFilled, masker.MaskFilledString)
-	masker.RegisterMaskStringFunc(mask.MaskTypeFixed, masker.MaskFixedString)
-	for _, p := range params {
-		masker.RegisterMaskField(p, "filled")
-		masker.RegisterMaskField(p, "fixed")
-	}
-
-	masked, err := masker.Mask(m)
+	masked, err := m.fields(params...).Mask(v)
 	if err != nil {
 		return nil, err
 	}
@@ -84,3 +249,44 @@ func JSONBytes(data []byte, params ...string) ([]byte, error) {
 
 	return mv, nil
 }
+
+// =============================================================================
+
+// Default returns the masker used by the package level functions.
+func Default() *Masker {
+	return defaultMasker
+}
+
+// Struct masks the struct value using the default masker.
+func Struct(v any, params ...string) (any, error) {
+	return defaultMasker.Struct(v, params...)
+}
+
+// StructWith masks the struct value with the mask type overrides using the
+// default masker.
+func StructWith(v any, overrides map[string]string) (any, error) {
+	return defaultMasker.StructWith(v, overrides)
+}
+
+// StructToByte masks the struct value using the default masker and returns
+// it as a JSON byte slice.
+func StructToByte(v any, params ...string) ([]byte, error) {
+	return defaultMasker.StructToByte(v, params...)
+}
+
+// JSONBytes masks the JSON document using the default masker.
+func JSONBytes(data []byte, params ...string) ([]byte, error) {
+	return defaultMasker.JSONBytes(data, params...)
+}
+
+// JSONBytesPath masks the values at the specified paths of the JSON document
+// using the default masker.
+func JSONBytesPath(data []byte, paths ...string) ([]byte, error) {
+	return defaultMasker.JSONBytesPath(data, paths...)
+}
+
+// JSONBytesRecursive masks the fields wherever they appear in the JSON
+// document using the default masker.
+func JSONBytesRecursive(data []byte, opts RecursiveOptions, fields ...string) ([]byte, error) {
+	return defaultMasker.JSONBytesRecursive(data, opts, fields...)
+}
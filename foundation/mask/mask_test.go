@@ -0,0 +1,134 @@
+package mask_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+)
+
+func TestRegisteredTypes(t *testing.T) {
+	m := mask.New()
+
+	want := []string{
+		mask.MaskTypeCard,
+		mask.MaskTypeEmail,
+		mask.MaskTypeFilled,
+		mask.MaskTypeFixed,
+		mask.MaskTypeHash,
+		mask.MaskTypeIP,
+		mask.MaskTypeName,
+		mask.MaskTypePhone,
+		mask.MaskTypeRUT,
+	}
+
+	if got := m.RegisteredTypes(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSample(t *testing.T) {
+	m := mask.New()
+
+	got, err := m.Sample(mask.MaskTypeEmail, "john.doe@example.com")
+	if err != nil {
+		t.Fatalf("sampling email: %s", err)
+	}
+
+	if want := "jo******@example.com"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	if _, err := m.Sample("unknown", "value"); err == nil {
+		t.Fatal("expected an error for an unknown mask type")
+	}
+}
+
+func TestRegisterPredicate(t *testing.T) {
+	m := mask.New()
+
+	looksLikeEmail := func(value string) bool {
+		return strings.Contains(value, "@")
+	}
+
+	if err := m.RegisterPredicate("pii", looksLikeEmail, mask.MaskTypeEmail); err != nil {
+		t.Fatalf("registering predicate: %s", err)
+	}
+
+	type ticket struct {
+		Notes string `mask:"pii"`
+	}
+
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{name: "matching value", notes: "john.doe@example.com", want: "jo******@example.com"},
+		{name: "non matching value", notes: "call me tomorrow", want: "call me tomorrow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := m.Struct(ticket{Notes: tt.notes})
+			if err != nil {
+				t.Fatalf("masking struct: %s", err)
+			}
+
+			if got := v.(ticket).Notes; got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRegisterPredicateConflicts(t *testing.T) {
+	m := mask.New()
+
+	always := func(string) bool { return true }
+
+	if err := m.RegisterPredicate("emailish", always, mask.MaskTypeEmail); err == nil {
+		t.Fatal("expected an error for a type starting with a registered type")
+	}
+
+	if err := m.RegisterPredicate("pii", always, "unknown"); err == nil {
+		t.Fatal("expected an error for an unknown base type")
+	}
+}
+
+func TestStructWith(t *testing.T) {
+	type contact struct {
+		Email string
+		Phone string
+		Notes string
+	}
+
+	c := contact{Email: "john.doe@example.com", Phone: "+56912345678", Notes: "call after 5"}
+
+	m := mask.New()
+
+	v, err := m.StructWith(c, map[string]string{"Email": mask.MaskTypeEmail, "Phone": mask.MaskTypePhone})
+	if err != nil {
+		t.Fatalf("masking struct: %s", err)
+	}
+
+	want := contact{Email: "jo******@example.com", Phone: "+56*****5678", Notes: "call after 5"}
+	if got := v.(contact); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	// The overrides of a call don't apply to the next ones.
+	v, err = m.Struct(c)
+	if err != nil {
+		t.Fatalf("masking struct: %s", err)
+	}
+
+	if got := v.(contact); got != c {
+		t.Fatalf("expected the overrides not to leak, got %+v", got)
+	}
+
+	if _, err := m.StructWith(c, map[string]string{"Email": "unknown"}); err == nil {
+		t.Fatal("expected an error for an unknown mask type")
+	}
+}
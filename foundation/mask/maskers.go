@@ -0,0 +1,240 @@
+package mask
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/netip"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Set of mask types provided by this package.
+const (
+	MaskTypePhone = "phone"
+	MaskTypeRUT   = "rut"
+	MaskTypeCard  = "card"
+	MaskTypeEmail = "email"
+	MaskTypeName  = "name"
+	MaskTypeIP    = "ip"
+	MaskTypeHash  = "hash"
+)
+
+// hashHexLen is the number of hex characters of the HMAC kept by the hash
+// mask type.
+const hashHexLen = 16
+
+// rutPattern matches a Chilean RUT in dotted or plain format, with or without
+// the dash before the verifier digit.
+var rutPattern = regexp.MustCompile(`^(\d{1,2})(\.?)(\d{3})\.?(\d{3})(-?)([\dkK])$`)
+
+// twoDigitCallingCodes holds the E.164 country calling codes with two digits.
+// Codes starting with 1 or 7 have a single digit and the rest have three.
+var twoDigitCallingCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true,
+	"34": true, "36": true, "39": true, "40": true, "41": true, "43": true,
+	"44": true, "45": true, "46": true, "47": true, "48": true, "49": true,
+	"51": true, "52": true, "53": true, "54": true, "55": true, "56": true,
+	"57": true, "58": true, "60": true, "61": true, "62": true, "63": true,
+	"64": true, "65": true, "66": true, "81": true, "82": true, "84": true,
+	"86": true, "90": true, "91": true, "92": true, "93": true, "94": true,
+	"95": true, "98": true,
+}
+
+// maskPhone keeps the country code, when the number starts with a plus sign,
+// and the last four digits of a phone number. The digits in between are
+// masked, e.g. "+56 9 1234 5678" becomes "+56*****5678".
+func (m *Masker) maskPhone(arg, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	const keep = 4
+
+	international := strings.HasPrefix(value, "+")
+
+	var digits strings.Builder
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		case r == '+' && i == 0:
+		default:
+			return "", errors.New("invalid phone number: unexpected character")
+		}
+	}
+	number := digits.String()
+
+	var countryCode string
+	if international {
+		countryCode = number[:callingCodeLen(number)]
+	}
+
+	masked := len(number) - len(countryCode) - keep
+	if masked <= 0 {
+		return "", errors.New("invalid phone number: too short")
+	}
+
+	var b strings.Builder
+	if international {
+		b.WriteString("+" + countryCode)
+	}
+	b.WriteString(strings.Repeat(m.masker.MaskChar(), masked))
+	b.WriteString(number[len(number)-keep:])
+
+	return b.String(), nil
+}
+
+// callingCodeLen returns the length of the E.164 country calling code at the
+// start of the digits.
+func callingCodeLen(digits string) int {
+	switch {
+	case len(digits) < 3:
+		return len(digits)
+	case digits[0] == '1' || digits[0] == '7':
+		return 1
+	case twoDigitCallingCodes[digits[:2]]:
+		return 2
+	}
+
+	return 3
+}
+
+// maskRUT keeps the first two digits and the verifier digit of a Chilean RUT
+// and masks the rest, e.g. "12.345.678-9" becomes "12.******-9".
+func (m *Masker) maskRUT(arg, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	parts := rutPattern.FindStringSubmatch(value)
+	if parts == nil {
+		return "", errors.New("invalid rut")
+	}
+
+	body := parts[1] + parts[3] + parts[4]
+	dot, dash, verifier := parts[2], parts[5], parts[6]
+
+	var b strings.Builder
+	b.WriteString(body[:2])
+	b.WriteString(dot)
+	b.WriteString(strings.Repeat(m.masker.MaskChar(), len(body)-2))
+	b.WriteString(dash)
+	b.WriteString(verifier)
+
+	return b.String(), nil
+}
+
+// maskCard keeps the first six and last four digits of a card number (PAN)
+// as allowed by PCI DSS and masks the rest. Spaces and dashes are removed and
+// numbers shorter than 12 digits are fully masked.
+func (m *Masker) maskCard(arg, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	const minLen, first, last = 12, 6, 4
+
+	var digits strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-':
+		default:
+			return "", errors.New("invalid card number: unexpected character")
+		}
+	}
+	pan := digits.String()
+
+	if len(pan) < minLen {
+		return strings.Repeat(m.masker.MaskChar(), len(pan)), nil
+	}
+
+	return pan[:first] + strings.Repeat(m.masker.MaskChar(), len(pan)-first-last) + pan[len(pan)-last:], nil
+}
+
+// maskEmail keeps the domain and the start of the local part of an email and
+// masks the rest of the local part, e.g. "john.doe@example.com" becomes
+// "jo******@example.com" with the default keep ratio.
+func (m *Masker) maskEmail(arg, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	at := strings.LastIndex(value, "@")
+	if at <= 0 || at == len(value)-1 {
+		return "", errors.New("invalid email")
+	}
+
+	local := []rune(value[:at])
+	keep := max(len(local)/m.emailKeepRatio, 1)
+
+	return string(local[:keep]) + strings.Repeat(m.masker.MaskChar(), len(local)-keep) + value[at:], nil
+}
+
+// maskName keeps the first letter of every word of a name and masks the
+// rest, counting characters instead of bytes so accented letters get a
+// single mask character, e.g. "José Muñoz" becomes "J*** M****". Names with
+// a single word are masked the same way.
+func (m *Masker) maskName(arg, value string) (string, error) {
+	words := strings.Fields(value)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat(m.masker.MaskChar(), utf8.RuneCountInString(word[size:]))
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// maskIP zeroes the host part of an IP address while keeping the network,
+// the last octet of an IPv4 address and the last 80 bits of an IPv6
+// address, e.g. "192.168.1.42" becomes "192.168.1.0".
+func (m *Masker) maskIP(arg, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return "", errors.New("invalid ip")
+	}
+	addr = addr.Unmap().WithZone("")
+
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return "", errors.New("invalid ip")
+	}
+
+	return prefix.Addr().String(), nil
+}
+
+// maskHash replaces the value with the start of its HMAC-SHA256, in hex,
+// keyed with the hash key of the masker. Equal values get equal hashes, so
+// they can still be grouped or joined, but the value can't be recovered
+// without the key. This is pseudonymization, not anonymization: anyone with
+// the key can hash candidate values and match them, so the key must be kept
+// secret and the hashes treated as personal data. It fails when the masker
+// has no hash key.
+func (m *Masker) maskHash(arg, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	if len(m.hashKey) == 0 {
+		return "", errors.New("hash key not configured")
+	}
+
+	mac := hmac.New(sha256.New, m.hashKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))[:hashHexLen], nil
+}
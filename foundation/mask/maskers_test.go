@@ -0,0 +1,301 @@
+package mask_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+)
+
+func TestMaskPhone(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "chilean mobile", input: "+56912345678", want: "+56*****5678"},
+		{name: "chilean formatted", input: "+56 9 1234 5678", want: "+56*****5678"},
+		{name: "us", input: "+1 (415) 555-2671", want: "+1******2671"},
+		{name: "national", input: "912345678", want: "*****5678"},
+		{name: "empty", input: "", want: ""},
+		{name: "letters", input: "+56 9 CALL ME", wantErr: true},
+		{name: "too short", input: "+561234", wantErr: true},
+		{name: "plus in the middle", input: "56+912345678", wantErr: true},
+	}
+
+	m := mask.New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Sample(mask.MaskTypePhone, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMaskRUT(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "dotted", input: "12.345.678-9", want: "12.******-9"},
+		{name: "plain", input: "12345678-9", want: "12******-9"},
+		{name: "no dash", input: "123456789", want: "12******9"},
+		{name: "seven digit body", input: "7.654.321-K", want: "76.*****-K"},
+		{name: "empty", input: "", want: ""},
+		{name: "letters", input: "12.ABC.678-9", wantErr: true},
+		{name: "too short", input: "1234-5", wantErr: true},
+	}
+
+	m := mask.New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Sample(mask.MaskTypeRUT, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMaskRUTTag(t *testing.T) {
+	type person struct {
+		RUT string `mask:"rut"`
+	}
+
+	v, err := mask.New().Struct(person{RUT: "12.345.678-9"})
+	if err != nil {
+		t.Fatalf("masking struct: %s", err)
+	}
+
+	if got := v.(person).RUT; got != "12.******-9" {
+		t.Fatalf("expected the rut tag to be applied, got %q", got)
+	}
+}
+
+func TestMaskCard(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "visa", input: "4111 1111 1111 1111", want: "411111******1111"},
+		{name: "amex", input: "3782-822463-10005", want: "378282*****0005"},
+		{name: "maestro 12 digits", input: "675964982643", want: "675964**2643"},
+		{name: "maestro 19 digits", input: "6759649826438453000", want: "675964*********3000"},
+		{name: "short", input: "12345678901", want: "***********"},
+		{name: "empty", input: "", want: ""},
+		{name: "letters", input: "4111-1111-ABCD-1111", wantErr: true},
+	}
+
+	m := mask.New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Sample(mask.MaskTypeCard, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMaskErrorsHideValue(t *testing.T) {
+	tests := []struct {
+		maskType string
+		input    string
+	}{
+		{maskType: mask.MaskTypePhone, input: "+56 9 8765 432#"},
+		{maskType: mask.MaskTypePhone, input: "123"},
+		{maskType: mask.MaskTypeRUT, input: "12.ABC.678-9"},
+		{maskType: mask.MaskTypeCard, input: "4111-1111-1111-111#"},
+		{maskType: mask.MaskTypeEmail, input: "ana.rojas"},
+		{maskType: mask.MaskTypeIP, input: "192.168.1.42:8080"},
+	}
+
+	m := mask.New()
+
+	for _, tt := range tests {
+		t.Run(tt.maskType, func(t *testing.T) {
+			_, err := m.Sample(tt.maskType, tt.input)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if strings.Contains(err.Error(), tt.input) || strings.Contains(err.Error(), "#") {
+				t.Fatalf("expected the error to leave the value out, got %q", err)
+			}
+		})
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "accents", input: "José Muñoz", want: "J*** M****"},
+		{name: "leading accent", input: "Ñuñoa Álvarez", want: "Ñ**** Á******"},
+		{name: "single word", input: "Ana", want: "A**"},
+		{name: "single letter", input: "J", want: "J"},
+		{name: "extra spaces", input: " María  José ", want: "M**** J***"},
+		{name: "empty", input: "", want: ""},
+	}
+
+	m := mask.New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Sample(mask.MaskTypeName, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMaskIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "ipv4", input: "192.168.1.42", want: "192.168.1.0"},
+		{name: "ipv6", input: "2001:db8:85a3:8d3:1319:8a2e:370:7348", want: "2001:db8:85a3::"},
+		{name: "ipv6 zone", input: "fe80::1%eth0", want: "fe80::"},
+		{name: "ipv4 mapped", input: "::ffff:10.1.2.3", want: "10.1.2.0"},
+		{name: "empty", input: "", want: ""},
+		{name: "with port", input: "192.168.1.42:8080", wantErr: true},
+		{name: "invalid", input: "300.1.1.1", wantErr: true},
+	}
+
+	m := mask.New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Sample(mask.MaskTypeIP, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMaskIPTag(t *testing.T) {
+	type access struct {
+		IP string `mask:"ip"`
+	}
+
+	v, err := mask.New().Struct(access{IP: "10.20.30.40"})
+	if err != nil {
+		t.Fatalf("masking struct: %s", err)
+	}
+
+	if got := v.(access).IP; got != "10.20.30.0" {
+		t.Fatalf("expected the ip tag to be applied, got %q", got)
+	}
+}
+
+func TestMaskHash(t *testing.T) {
+	const email = "jane.doe@example.com"
+
+	type event struct {
+		Email string `mask:"hash"`
+	}
+
+	m := mask.New(mask.WithHashKey([]byte("analytics")))
+
+	first, err := m.Struct(event{Email: email})
+	if err != nil {
+		t.Fatalf("masking struct: %s", err)
+	}
+
+	second, err := m.Sample(mask.MaskTypeHash, email)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	hash := first.(event).Email
+	if hash != second || len(hash) != 16 || hash == email {
+		t.Fatalf("expected the same 16 character hash for the same email, got %q and %q", hash, second)
+	}
+
+	other, err := m.Sample(mask.MaskTypeHash, "john.doe@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if other == hash {
+		t.Fatal("expected different emails to get different hashes")
+	}
+
+	rekeyed, err := mask.New(mask.WithHashKey([]byte("other"))).Sample(mask.MaskTypeHash, email)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if rekeyed == hash {
+		t.Fatal("expected the hash to depend on the key")
+	}
+
+	if _, err := mask.New().Sample(mask.MaskTypeHash, email); err == nil {
+		t.Fatal("expected an error without a hash key")
+	}
+}
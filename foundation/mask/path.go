@@ -0,0 +1,129 @@
+package mask
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// pathTypes maps the field names, in lower case, to the mask type applied by
+// JSONBytesPath. Fields not listed here are masked as fixed.
+var pathTypes = map[string]string{
+	"email":      MaskTypeEmail,
+	"phone":      MaskTypePhone,
+	"mobile":     MaskTypePhone,
+	"rut":        MaskTypeRUT,
+	"card":       MaskTypeCard,
+	"cardnumber": MaskTypeCard,
+	"pan":        MaskTypeCard,
+}
+
+// JSONBytesPath takes a JSON byte slice and a list of dotted paths like
+// "user.contact.email" or "items[].cardNumber", where [] walks every element
+// of an array. The value found at each path is masked with the mask type
+// inferred from its field name. Paths that don't exist in the document are
+// ignored, and values the inferred mask type can't handle are masked as
+// fixed.
+func (m *Masker) JSONBytesPath(data []byte, paths ...string) ([]byte, error) {
+	var v any
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		v = m.maskPath(v, segments, pathType(segments[len(segments)-1]))
+	}
+
+	mv, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return mv, nil
+}
+
+// maskPath walks the value following the segments and returns it with the
+// value at the end of the path masked.
+func (m *Masker) maskPath(v any, segments []string, maskType string) any {
+	if len(segments) == 0 {
+		return m.maskLeaf(v, maskType)
+	}
+
+	key, isArray := strings.CutSuffix(segments[0], "[]")
+
+	if key != "" {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+
+		child, exists := obj[key]
+		if !exists {
+			return v
+		}
+
+		if !isArray {
+			obj[key] = m.maskPath(child, segments[1:], maskType)
+			return obj
+		}
+
+		obj[key] = m.maskElements(child, segments[1:], maskType)
+		return obj
+	}
+
+	if isArray {
+		return m.maskElements(v, segments[1:], maskType)
+	}
+
+	return v
+}
+
+// maskElements applies maskPath to every element of an array value.
+func (m *Masker) maskElements(v any, segments []string, maskType string) any {
+	arr, ok := v.([]any)
+	if !ok {
+		return v
+	}
+
+	for i := range arr {
+		arr[i] = m.maskPath(arr[i], segments, maskType)
+	}
+
+	return arr
+}
+
+// maskLeaf masks string and number values. Objects, arrays and null values
+// are left untouched.
+func (m *Masker) maskLeaf(v any, maskType string) any {
+	var s string
+	switch value := v.(type) {
+	case string:
+		s = value
+	case json.Number:
+		s = value.String()
+	default:
+		return v
+	}
+
+	masked, err := m.funcs[maskType]("", s)
+	if err != nil {
+		masked, _ = m.funcs[MaskTypeFixed]("", s)
+	}
+
+	return masked
+}
+
+// pathType infers the mask type from the field name at the end of a path.
+func pathType(segment string) string {
+	name := strings.ToLower(strings.TrimSuffix(segment, "[]"))
+
+	if maskType, exists := pathTypes[name]; exists {
+		return maskType
+	}
+
+	return MaskTypeFixed
+}
@@ -0,0 +1,85 @@
+package mask_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+)
+
+func TestJSONBytesPath(t *testing.T) {
+	data := []byte(`{
+		"user": {
+			"name": "John",
+			"contact": {"email": "john.doe@example.com", "phone": "+56912345678"}
+		},
+		"items": [
+			{"cardNumber": "4111111111111111", "company": "ACME"},
+			{"cardNumber": "378282246310005", "company": "Initech"}
+		],
+		"truth": "kept"
+	}`)
+
+	got, err := mask.New().JSONBytesPath(data,
+		"user.contact.email",
+		"user.contact.phone",
+		"items[].cardNumber",
+		"user.missing.email",
+		"nothing[].here",
+	)
+	if err != nil {
+		t.Fatalf("masking paths: %s", err)
+	}
+
+	want := `{
+		"user": {
+			"name": "John",
+			"contact": {"email": "jo******@example.com", "phone": "+56*****5678"}
+		},
+		"items": [
+			{"cardNumber": "411111******1111", "company": "ACME"},
+			{"cardNumber": "378282*****0005", "company": "Initech"}
+		],
+		"truth": "kept"
+	}`
+
+	assertJSONEqual(t, want, got)
+}
+
+func TestJSONBytesPathExactFieldNames(t *testing.T) {
+	data := []byte(`{"company": "ACME", "truth": "12.345.678-9", "span": "abc"}`)
+
+	got, err := mask.New().JSONBytesPath(data, "company", "truth", "span")
+	if err != nil {
+		t.Fatalf("masking paths: %s", err)
+	}
+
+	// Field names merely containing a known name are masked as fixed.
+	var doc map[string]string
+	if err := json.Unmarshal(got, &doc); err != nil {
+		t.Fatalf("decoding result: %s", err)
+	}
+
+	for field, value := range doc {
+		if value != "********" {
+			t.Errorf("expected %s to be masked as fixed, got %q", field, value)
+		}
+	}
+}
+
+func assertJSONEqual(t *testing.T, want string, got []byte) {
+	t.Helper()
+
+	var w, g any
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("decoding expected document: %s", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("decoding document: %s", err)
+	}
+
+	if !reflect.DeepEqual(w, g) {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,126 @@
+package mask
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// defaultMaxDepth is the depth limit used by JSONBytesRecursive when none is
+// provided.
+const defaultMaxDepth = 32
+
+// ErrMaxDepth is returned when a JSON document is nested deeper than the
+// depth limit.
+var ErrMaxDepth = errors.New("json document exceeds the maximum depth")
+
+// RecursiveOptions configures the traversal of JSONBytesRecursive.
+type RecursiveOptions struct {
+	// MaxDepth limits how deep objects and arrays can be nested. Zero means
+	// the default of 32.
+	MaxDepth int
+
+	// Exclude lists the keys whose values are left untouched, including
+	// anything nested inside them.
+	Exclude []string
+}
+
+// JSONBytesRecursive takes a JSON byte slice and a list of field names. The
+// values of the fields are masked as fixed wherever they appear in the
+// document, at any depth inside objects and arrays, and when a field holds
+// an object or an array every value inside it is masked. Unlike JSONBytes
+// the document can be an array, the keys listed in the options are skipped
+// and documents deeper than the limit are rejected with ErrMaxDepth instead
+// of being walked.
+func (m *Masker) JSONBytesRecursive(data []byte, opts RecursiveOptions, fields ...string) ([]byte, error) {
+	var v any
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	w := walker{
+		masker:   m,
+		maxDepth: opts.MaxDepth,
+		fields:   make(map[string]bool, len(fields)),
+		exclude:  make(map[string]bool, len(opts.Exclude)),
+	}
+
+	if w.maxDepth <= 0 {
+		w.maxDepth = defaultMaxDepth
+	}
+	for _, f := range fields {
+		w.fields[f] = true
+	}
+	for _, k := range opts.Exclude {
+		w.exclude[k] = true
+	}
+
+	v, err := w.walk(v, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mv, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return mv, nil
+}
+
+// walker holds the state of a JSONBytesRecursive traversal.
+type walker struct {
+	masker   *Masker
+	maxDepth int
+	fields   map[string]bool
+	exclude  map[string]bool
+}
+
+// walk returns the value with the matching fields masked. Every value is
+// masked when mask is true, because it's nested inside a matching field.
+func (w walker) walk(v any, depth int, mask bool) (any, error) {
+	switch value := v.(type) {
+	case map[string]any:
+		if depth >= w.maxDepth {
+			return nil, ErrMaxDepth
+		}
+
+		for k, child := range value {
+			if w.exclude[k] {
+				continue
+			}
+
+			masked, err := w.walk(child, depth+1, mask || w.fields[k])
+			if err != nil {
+				return nil, err
+			}
+			value[k] = masked
+		}
+
+		return value, nil
+
+	case []any:
+		if depth >= w.maxDepth {
+			return nil, ErrMaxDepth
+		}
+
+		for i, child := range value {
+			masked, err := w.walk(child, depth+1, mask)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = masked
+		}
+
+		return value, nil
+	}
+
+	if !mask {
+		return v, nil
+	}
+
+	return w.masker.maskLeaf(v, MaskTypeFixed), nil
+}
@@ -0,0 +1,59 @@
+package mask_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+)
+
+func TestJSONBytesRecursive(t *testing.T) {
+	data := []byte(`{
+		"token": "abc",
+		"user": {
+			"name": "John",
+			"sessions": [{"token": "def", "device": "ios"}]
+		},
+		"secrets": {"token": 123, "nested": ["a", "b"]},
+		"audit": {"token": "kept"}
+	}`)
+
+	got, err := mask.New().JSONBytesRecursive(data, mask.RecursiveOptions{Exclude: []string{"audit"}}, "token", "secrets")
+	if err != nil {
+		t.Fatalf("masking: %s", err)
+	}
+
+	want := `{
+		"token": "********",
+		"user": {
+			"name": "John",
+			"sessions": [{"token": "********", "device": "ios"}]
+		},
+		"secrets": {"token": "********", "nested": ["********", "********"]},
+		"audit": {"token": "kept"}
+	}`
+
+	assertJSONEqual(t, want, got)
+}
+
+func TestJSONBytesRecursiveArray(t *testing.T) {
+	got, err := mask.New().JSONBytesRecursive([]byte(`[{"token": "abc"}, {"other": "def"}]`), mask.RecursiveOptions{}, "token")
+	if err != nil {
+		t.Fatalf("masking: %s", err)
+	}
+
+	assertJSONEqual(t, `[{"token": "********"}, {"other": "def"}]`, got)
+}
+
+func TestJSONBytesRecursiveMaxDepth(t *testing.T) {
+	data := []byte(strings.Repeat(`{"a":`, 5) + `"x"` + strings.Repeat(`}`, 5))
+
+	if _, err := mask.New().JSONBytesRecursive(data, mask.RecursiveOptions{MaxDepth: 4}, "a"); !errors.Is(err, mask.ErrMaxDepth) {
+		t.Fatalf("expected ErrMaxDepth, got %v", err)
+	}
+
+	if _, err := mask.New().JSONBytesRecursive(data, mask.RecursiveOptions{MaxDepth: 5}, "a"); err != nil {
+		t.Fatalf("expected the document within the limit to be masked, got %v", err)
+	}
+}
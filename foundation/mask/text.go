@@ -0,0 +1,78 @@
+package mask
+
+import "regexp"
+
+// textPatterns holds the sensitive values recognized inside free text, like
+// error messages, and the mask type applied to each of them. Emails go first
+// so their digits aren't taken as part of a RUT or a card number.
+var textPatterns = []struct {
+	re       *regexp.Regexp
+	maskType string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), MaskTypeEmail},
+	{regexp.MustCompile(`\b\d{1,2}\.?\d{3}\.?\d{3}-[\dkK]\b`), MaskTypeRUT},
+	{regexp.MustCompile(`\b\d(?:[ \-]?\d){11,18}\b`), MaskTypeCard},
+}
+
+// Text masks the emails, RUTs and card numbers found in free text. Values
+// that can't be masked by their mask type are masked as fixed.
+func (m *Masker) Text(s string) string {
+	for _, p := range textPatterns {
+		fn := m.funcs[p.maskType]
+		s = p.re.ReplaceAllStringFunc(s, func(value string) string {
+			masked, err := fn("", value)
+			if err != nil {
+				masked, _ = m.funcs[MaskTypeFixed]("", value)
+			}
+			return masked
+		})
+	}
+
+	return s
+}
+
+// maskedError holds an error whose message has been masked.
+type maskedError struct {
+	err error
+	msg string
+}
+
+// Error implements the error interface.
+func (me *maskedError) Error() string {
+	return me.msg
+}
+
+// Unwrap returns the original error, so errors.Is and errors.As keep working.
+func (me *maskedError) Unwrap() error {
+	return me.err
+}
+
+// Error wraps the error so its message has the sensitive values masked,
+// making it safe to be logged or returned to clients.
+func (m *Masker) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	masked := m.Text(msg)
+	if masked == msg {
+		return err
+	}
+
+	return &maskedError{err: err, msg: masked}
+}
+
+// =============================================================================
+
+// Text masks the sensitive values found in free text using the default
+// masker.
+func Text(s string) string {
+	return defaultMasker.Text(s)
+}
+
+// Error masks the sensitive values in the error message using the default
+// masker.
+func Error(err error) error {
+	return defaultMasker.Error(err)
+}
@@ -0,0 +1,106 @@
+package mask
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// tokenBytes is the number of bytes of the HMAC kept in the tokens.
+const tokenBytes = 16
+
+// TokenStore stores the values behind the tokens so they can be recovered.
+type TokenStore interface {
+	Put(token string, value string)
+	Get(token string) (string, bool)
+}
+
+// Tokenizer replaces sensitive values with tokens that can be resolved back
+// to the original values, unlike masking. Tokens are derived from the value
+// with a keyed HMAC, so the same value always gets the same token and logs
+// can still be correlated. It's never used by the Masker, the callers opt in
+// by tokenizing the values themselves and should only expose Detokenize
+// behind access control.
+type Tokenizer struct {
+	key   []byte
+	store TokenStore
+}
+
+// NewTokenizer constructs a Tokenizer keeping the values in the store. A
+// random key is generated when key is empty, in that case the tokens change
+// every time the service restarts.
+func NewTokenizer(store TokenStore, key []byte) (*Tokenizer, error) {
+	if len(key) == 0 {
+		key = make([]byte, sha256.Size)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	t := Tokenizer{
+		key:   key,
+		store: store,
+	}
+
+	return &t, nil
+}
+
+// Tokenize returns the token for the value, prefixed by the field type so
+// operators can tell what it stands for, e.g. "rut:3f2a...". Empty values are
+// returned as they are.
+func (t *Tokenizer) Tokenize(value string, fieldType string) string {
+	if value == "" {
+		return value
+	}
+
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(fieldType))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+
+	token := fieldType + ":" + hex.EncodeToString(mac.Sum(nil)[:tokenBytes])
+	t.store.Put(token, value)
+
+	return token
+}
+
+// Detokenize returns the value behind the token. It returns false when the
+// token is unknown.
+func (t *Tokenizer) Detokenize(token string) (string, bool) {
+	return t.store.Get(token)
+}
+
+// =============================================================================
+
+// MemTokenStore is a TokenStore that keeps the values in memory. The values
+// are lost when the service stops.
+type MemTokenStore struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewMemTokenStore constructs an empty in-memory token store.
+func NewMemTokenStore() *MemTokenStore {
+	return &MemTokenStore{
+		values: make(map[string]string),
+	}
+}
+
+// Put implements the TokenStore interface.
+func (s *MemTokenStore) Put(token string, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[token] = value
+}
+
+// Get implements the TokenStore interface.
+func (s *MemTokenStore) Get(token string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, exists := s.values[token]
+	return value, exists
+}
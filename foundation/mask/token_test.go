@@ -0,0 +1,61 @@
+package mask_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+)
+
+func TestTokenize(t *testing.T) {
+	const rut = "12.345.678-5"
+
+	tk, err := mask.NewTokenizer(mask.NewMemTokenStore(), []byte("test key"))
+	if err != nil {
+		t.Fatalf("constructing tokenizer: %s", err)
+	}
+
+	token := tk.Tokenize(rut, mask.MaskTypeRUT)
+	if !strings.HasPrefix(token, mask.MaskTypeRUT+":") || strings.Contains(token, rut) {
+		t.Fatalf("expected a rut token without the value, got %q", token)
+	}
+
+	if again := tk.Tokenize(rut, mask.MaskTypeRUT); again != token {
+		t.Fatalf("expected the same token for the same value, got %q and %q", token, again)
+	}
+
+	if other := tk.Tokenize(rut, mask.MaskTypeFixed); other == token {
+		t.Fatal("expected a different token for another field type")
+	}
+
+	value, ok := tk.Detokenize(token)
+	if !ok || value != rut {
+		t.Fatalf("expected the token to resolve to %q, got %q, %t", rut, value, ok)
+	}
+
+	if _, ok := tk.Detokenize("rut:unknown"); ok {
+		t.Fatal("expected an unknown token not to resolve")
+	}
+
+	if empty := tk.Tokenize("", mask.MaskTypeRUT); empty != "" {
+		t.Fatalf("expected empty values to be kept, got %q", empty)
+	}
+}
+
+func TestTokenizeKeys(t *testing.T) {
+	store := mask.NewMemTokenStore()
+
+	tk1, err := mask.NewTokenizer(store, nil)
+	if err != nil {
+		t.Fatalf("constructing tokenizer: %s", err)
+	}
+
+	tk2, err := mask.NewTokenizer(store, nil)
+	if err != nil {
+		t.Fatalf("constructing tokenizer: %s", err)
+	}
+
+	if tk1.Tokenize("Ana", mask.MaskTypeName) == tk2.Tokenize("Ana", mask.MaskTypeName) {
+		t.Fatal("expected tokenizers with random keys to produce different tokens")
+	}
+}
@@ -0,0 +1,88 @@
+// Package metrics provides support for metric types not covered by expvar,
+// which can be published next to the expvar counters.
+package metrics
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultBuckets holds the upper bounds, in seconds, meant for the latency
+// of http requests.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts the observed values in cumulative buckets, along with
+// their count and sum, the same way Prometheus histograms do. It implements
+// the expvar.Var interface so it can be published in expvar.
+type Histogram struct {
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []int64
+	count   int64
+	sum     float64
+}
+
+// NewHistogram constructs a histogram with the provided bucket upper bounds.
+// The DefaultBuckets are used when none are provided.
+func NewHistogram(bounds ...float64) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultBuckets
+	}
+
+	sorted := make([]float64, len(bounds))
+	copy(sorted, bounds)
+	sort.Float64s(sorted)
+
+	return &Histogram{
+		bounds:  sorted,
+		buckets: make([]int64, len(sorted)),
+	}
+}
+
+// Observe adds the value to the histogram.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += v
+
+	for i := sort.SearchFloat64s(h.bounds, v); i < len(h.bounds); i++ {
+		h.buckets[i]++
+	}
+}
+
+// Count returns the number of observed values.
+func (h *Histogram) Count() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.count
+}
+
+// String implements the expvar.Var interface rendering the histogram as
+// JSON, with the buckets keyed by their upper bound.
+func (h *Histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buckets := make(map[string]int64, len(h.bounds)+1)
+	for i, b := range h.bounds {
+		buckets[strconv.FormatFloat(b, 'g', -1, 64)] = h.buckets[i]
+	}
+	buckets["+Inf"] = h.count
+
+	data, _ := json.Marshal(struct {
+		Count   int64            `json:"count"`
+		Sum     float64          `json:"sum"`
+		Buckets map[string]int64 `json:"buckets"`
+	}{
+		Count:   h.count,
+		Sum:     h.sum,
+		Buckets: buckets,
+	})
+
+	return string(data)
+}
@@ -0,0 +1,44 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/metrics"
+)
+
+func TestHistogram(t *testing.T) {
+	h := metrics.NewHistogram(1, 0.1, 0.5)
+
+	for _, v := range []float64{0.05, 0.1, 0.3, 0.7, 2} {
+		h.Observe(v)
+	}
+
+	// The histogram must be usable as an expvar.
+	var _ expvar.Var = h
+
+	var got struct {
+		Count   int64            `json:"count"`
+		Sum     float64          `json:"sum"`
+		Buckets map[string]int64 `json:"buckets"`
+	}
+	if err := json.Unmarshal([]byte(h.String()), &got); err != nil {
+		t.Fatalf("decoding histogram: %s", err)
+	}
+
+	if got.Count != 5 || h.Count() != 5 {
+		t.Fatalf("expected 5 values, got %d", got.Count)
+	}
+
+	if got.Sum < 3.14 || got.Sum > 3.16 {
+		t.Fatalf("expected a sum of 3.15, got %f", got.Sum)
+	}
+
+	expected := map[string]int64{"0.1": 2, "0.5": 3, "1": 4, "+Inf": 5}
+	for bound, n := range expected {
+		if got.Buckets[bound] != n {
+			t.Fatalf("expected %d values up to %s, got %d", n, bound, got.Buckets[bound])
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package otel provides support for initializing the OpenTelemetry tracing.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config defines the information needed to send the traces.
+type Config struct {
+	ServiceName string
+	ReporterURI string
+	Probability float64
+}
+
+// StartTracing configures the global tracer provider to export the traces
+// to the reporter URI through OTLP gRPC, sampling the given probability of
+// the traces that don't come with a sampling decision. The returned function
+// must be called on shutdown to send the pending spans.
+func StartTracing(cfg Config) (func(ctx context.Context) error, error) {
+	exporter, err := otlptracegrpc.New(
+		context.Background(),
+		otlptracegrpc.WithEndpoint(cfg.ReporterURI),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating new exporter: %w", err)
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Probability))),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns a tracer of the global tracer provider. It returns a no-op
+// tracer when StartTracing wasn't called.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
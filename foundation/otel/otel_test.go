@@ -0,0 +1,32 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/otel"
+)
+
+func TestStartTracing(t *testing.T) {
+	shutdown, err := otel.StartTracing(otel.Config{
+		ServiceName: "test",
+		ReporterURI: "localhost:4317",
+		Probability: 1,
+	})
+	if err != nil {
+		t.Fatalf("starting tracing: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	defer shutdown(ctx)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	if !span.SpanContext().IsValid() || !span.SpanContext().IsSampled() {
+		t.Fatal("expected a sampled span from the configured provider")
+	}
+}
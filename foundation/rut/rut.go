@@ -0,0 +1,90 @@
+// Package rut provides support for validating Chilean RUT numbers.
+package rut
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalid is returned when a value is not a valid RUT.
+var ErrInvalid = errors.New("invalid rut")
+
+// Validate checks the value is a well formed RUT whose verifier digit
+// matches the mod 11 algorithm. Both the dotted (12.345.678-5) and plain
+// (12345678-5) formats are accepted.
+func Validate(rut string) error {
+	body, dv, err := split(rut)
+	if err != nil {
+		return err
+	}
+
+	if verifier(body) != dv {
+		return fmt.Errorf("%w: verifier digit mismatch", ErrInvalid)
+	}
+
+	return nil
+}
+
+// Normalize validates the value and returns it in the canonical format used
+// to store RUTs, without dots and with a dash before the uppercase verifier
+// digit, like 12345678-5.
+func Normalize(rut string) (string, error) {
+	if err := Validate(rut); err != nil {
+		return "", err
+	}
+
+	body, dv, _ := split(rut)
+	if trimmed := strings.TrimLeft(body, "0"); trimmed != "" {
+		body = trimmed
+	}
+
+	return body + "-" + string(dv), nil
+}
+
+// split removes the format from the value and returns the digits of the
+// body and the verifier digit.
+func split(rut string) (string, byte, error) {
+	clean := strings.ToUpper(strings.NewReplacer(".", "", "-", "", " ", "").Replace(rut))
+
+	if len(clean) < 2 || len(clean) > 9 {
+		return "", 0, fmt.Errorf("%w: unexpected length", ErrInvalid)
+	}
+
+	body, dv := clean[:len(clean)-1], clean[len(clean)-1]
+
+	for i := 0; i < len(body); i++ {
+		if body[i] < '0' || body[i] > '9' {
+			return "", 0, fmt.Errorf("%w: body must be numeric", ErrInvalid)
+		}
+	}
+
+	if (dv < '0' || dv > '9') && dv != 'K' {
+		return "", 0, fmt.Errorf("%w: verifier digit must be numeric or K", ErrInvalid)
+	}
+
+	return body, dv, nil
+}
+
+// verifier calculates the verifier digit of the body using the mod 11
+// algorithm.
+func verifier(body string) byte {
+	sum, factor := 0, 2
+	for i := len(body) - 1; i >= 0; i-- {
+		sum += int(body[i]-'0') * factor
+
+		factor++
+		if factor > 7 {
+			factor = 2
+		}
+	}
+
+	switch r := 11 - sum%11; r {
+	case 11:
+		return '0'
+	case 10:
+		return 'K'
+	default:
+		return byte('0' + r)
+	}
+}
@@ -0,0 +1,70 @@
+package rut_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/rut"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{value: "12.345.678-5", valid: true},
+		{value: "12345678-5", valid: true},
+		{value: "123456785", valid: true},
+		{value: "11.111.111-1", valid: true},
+		{value: "7.775.735-k", valid: true},
+		{value: "7775735-K", valid: true},
+		{value: "12.345.678-9", valid: false},
+		{value: "12.345.678-", valid: false},
+		{value: "1A.345.678-5", valid: false},
+		{value: "1234567890-1", valid: false},
+		{value: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := rut.Validate(tt.value)
+
+			if tt.valid && err != nil {
+				t.Fatalf("expected %s to be valid, got %s", tt.value, err)
+			}
+
+			if !tt.valid && !errors.Is(err, rut.ErrInvalid) {
+				t.Fatalf("expected ErrInvalid for %s, got %v", tt.value, err)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "12.345.678-5", expected: "12345678-5"},
+		{value: "12345678-5", expected: "12345678-5"},
+		{value: "123456785", expected: "12345678-5"},
+		{value: "7.775.735-k", expected: "7775735-K"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := rut.Normalize(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := rut.Normalize("12.345.678-9"); !errors.Is(err, rut.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package semver provides support for parsing and comparing semantic versions.
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is returned when a value is not a semantic version.
+var ErrInvalid = errors.New("invalid semantic version")
+
+// Version represents a semantic version like 1.10.2 or 2.0.0-beta.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+}
+
+// Parse parses a version in the form major[.minor[.patch]][-prerelease],
+// with an optional leading v. Missing minor and patch numbers are zero and
+// build metadata after a plus sign is ignored.
+func Parse(s string) (Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, _ := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		nums[i] = n
+	}
+
+	v := Version{
+		Major:      nums[0],
+		Minor:      nums[1],
+		Patch:      nums[2],
+		PreRelease: pre,
+	}
+
+	return v, nil
+}
+
+// String returns the version in the form major.minor.patch[-prerelease].
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is lower, equal or
+// greater than o. A pre-release is lower than its release and pre-releases
+// are compared by their dot separated identifiers, numerically when both are
+// numbers, so beta.2 is lower than beta.10.
+func (v Version) Compare(o Version) int {
+	for _, d := range [3]int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+
+	switch {
+	case v.PreRelease == o.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case o.PreRelease == "":
+		return -1
+	}
+
+	return comparePreRelease(v.PreRelease, o.PreRelease)
+}
+
+// comparePreRelease compares two pre-releases identifier by identifier as
+// defined by semver. Numeric identifiers are lower than alphanumeric ones and
+// a pre-release with fewer identifiers is lower when the rest are equal.
+func comparePreRelease(a string, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+
+	return 0
+}
+
+// Less reports whether v is lower than o.
+func (v Version) Less(o Version) bool {
+	return v.Compare(o) < 0
+}
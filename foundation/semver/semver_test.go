@@ -0,0 +1,54 @@
+package semver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/semver"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "1.9.0", b: "1.10.0", expected: -1},
+		{a: "1.10.0", b: "1.9.0", expected: 1},
+		{a: "v2.1", b: "2.1.0", expected: 0},
+		{a: "2.0.0-beta", b: "2.0.0", expected: -1},
+		{a: "2.0.0-alpha", b: "2.0.0-beta", expected: -1},
+		{a: "2.0.0-beta.2", b: "2.0.0-beta.10", expected: -1},
+		{a: "2.0.0-beta.10", b: "2.0.0-beta.2", expected: 1},
+		{a: "2.0.0-beta.11", b: "2.0.0-beta.11", expected: 0},
+		{a: "2.0.0-alpha", b: "2.0.0-alpha.1", expected: -1},
+		{a: "2.0.0-1", b: "2.0.0-alpha", expected: -1},
+		{a: "2.0.0-beta.rc", b: "2.0.0-beta.2", expected: 1},
+		{a: "1.2.3+build.5", b: "1.2.3", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			a, err := semver.Parse(tt.a)
+			if err != nil {
+				t.Fatalf("parsing %s: %s", tt.a, err)
+			}
+
+			b, err := semver.Parse(tt.b)
+			if err != nil {
+				t.Fatalf("parsing %s: %s", tt.b, err)
+			}
+
+			if got := a.Compare(b); got != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, v := range []string{"", "abc", "1.x.0", "1.2.3.4", "1.-2.0"} {
+		if _, err := semver.Parse(v); !errors.Is(err, semver.ErrInvalid) {
+			t.Errorf("%q: expected ErrInvalid, got %v", v, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package timecl
+
+import "time"
+
+// IsBusinessDay reports whether the date, in Chilean time, falls between
+// Monday and Friday.
+func IsBusinessDay(t time.Time) bool {
+	return IsBusinessDayWith(t, nil)
+}
+
+// IsBusinessDayWith reports whether the date, in Chilean time, falls between
+// Monday and Friday and is not part of the holidays set. The holidays are
+// keyed by date using DateLayout, like "2024-09-18".
+func IsBusinessDayWith(t time.Time, holidays map[string]bool) bool {
+	t = Convert(t)
+
+	switch t.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+
+	return !holidays[t.Format(DateLayout)]
+}
+
+// AddBusinessDays returns the time n business days after t, skipping
+// weekends. A negative n moves backwards.
+func AddBusinessDays(t time.Time, n int) time.Time {
+	return AddBusinessDaysWith(t, n, nil)
+}
+
+// AddBusinessDaysWith returns the time n business days after t, skipping
+// weekends and the holidays set. A negative n moves backwards. The holidays
+// are keyed by date using DateLayout, so the official list can be kept
+// outside of this package.
+func AddBusinessDaysWith(t time.Time, n int, holidays map[string]bool) time.Time {
+	t = Convert(t)
+
+	step := 1
+	if n < 0 {
+		step = -1
+	}
+
+	for n != 0 {
+		t = t.AddDate(0, 0, step)
+		if IsBusinessDayWith(t, holidays) {
+			n -= step
+		}
+	}
+
+	return t
+}
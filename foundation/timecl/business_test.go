@@ -0,0 +1,58 @@
+package timecl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/timecl"
+)
+
+func TestAddBusinessDays(t *testing.T) {
+	holidays := map[string]bool{"2024-09-18": true, "2024-09-19": true, "2024-09-20": true}
+
+	tests := []struct {
+		name     string
+		from     string
+		n        int
+		holidays map[string]bool
+		expected string
+	}{
+		{name: "same week", from: "2024-06-03", n: 2, expected: "2024-06-05"},
+		{name: "landing on friday", from: "2024-06-04", n: 3, expected: "2024-06-07"},
+		{name: "crossing a weekend", from: "2024-06-06", n: 2, expected: "2024-06-10"},
+		{name: "from a saturday", from: "2024-06-08", n: 1, expected: "2024-06-10"},
+		{name: "backwards", from: "2024-06-10", n: -1, expected: "2024-06-07"},
+		{name: "skipping holidays", from: "2024-09-17", n: 1, holidays: holidays, expected: "2024-09-23"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := timecl.ParseInZone(timecl.DateLayout, tt.from)
+			if err != nil {
+				t.Fatalf("parsing: %s", err)
+			}
+
+			got := timecl.FormatDate(timecl.AddBusinessDaysWith(from, tt.n, tt.holidays))
+			if got != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsBusinessDay(t *testing.T) {
+	// 2024-06-08 00:30 in Chile is still Friday in UTC.
+	saturday := time.Date(2024, time.June, 8, 4, 30, 0, 0, time.UTC)
+	if timecl.IsBusinessDay(saturday) {
+		t.Fatal("expected saturday in Chile not to be a business day")
+	}
+
+	friday := time.Date(2024, time.June, 7, 12, 0, 0, 0, time.UTC)
+	if !timecl.IsBusinessDay(friday) {
+		t.Fatal("expected friday to be a business day")
+	}
+
+	if timecl.IsBusinessDayWith(friday, map[string]bool{"2024-06-07": true}) {
+		t.Fatal("expected a holiday not to be a business day")
+	}
+}
@@ -1,12 +1,29 @@
 // Package timecl contains a set of functions to get the current time in Chile.
 package timecl
 
-import "time"
+import (
+	"time"
+	_ "time/tzdata"
+)
 
-// timezone variable should be adjusted to reflect the Chilean timezone, which is GMT-4.
-// It is important to ensure that these settings are kept up-to-date based on any government
-// decisions that may impact the timezone.
-var timezone = time.FixedZone("GMT-3", -4*60*60)
+// DateLayout is the format used for dates without time, also used for the
+// keys of a holidays set.
+const DateLayout = "2006-01-02"
+
+// timezone holds the Chilean location, which switches between GMT-4 and
+// GMT-3 following the daylight saving time rules defined by the government.
+// The time zone database is embedded in the binary, so the fixed GMT-4 zone
+// is only a last resort.
+var timezone = loadLocation()
+
+func loadLocation() *time.Location {
+	loc, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		return time.FixedZone("GMT-4", -4*60*60)
+	}
+
+	return loc
+}
 
 // Now function returns the current time in Chile.
 func Now() time.Time {
@@ -17,3 +34,19 @@ func Now() time.Time {
 func Convert(t time.Time) time.Time {
 	return t.In(timezone)
 }
+
+// FormatRFC3339 returns the time formatted as RFC3339 in Chilean time.
+func FormatRFC3339(t time.Time) string {
+	return Convert(t).Format(time.RFC3339)
+}
+
+// FormatDate returns the date formatted as 2006-01-02 in Chilean time.
+func FormatDate(t time.Time) string {
+	return Convert(t).Format(DateLayout)
+}
+
+// ParseInZone parses the value using the layout. Values without zone
+// information are interpreted as Chilean time.
+func ParseInZone(layout, value string) (time.Time, error) {
+	return time.ParseInLocation(layout, value, timezone)
+}
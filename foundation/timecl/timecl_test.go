@@ -0,0 +1,65 @@
+package timecl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/timecl"
+)
+
+func TestConvertOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		t      time.Time
+		offset int
+	}{
+		{name: "summer", t: time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC), offset: -3 * 60 * 60},
+		{name: "winter", t: time.Date(2024, time.July, 15, 12, 0, 0, 0, time.UTC), offset: -4 * 60 * 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, offset := timecl.Convert(tt.t).Zone()
+			if offset != tt.offset {
+				t.Fatalf("expected offset %d, got %d", tt.offset, offset)
+			}
+		})
+	}
+}
+
+func TestParseInZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Time
+	}{
+		{name: "winter", value: "2024-06-01 10:00:00", expected: time.Date(2024, time.June, 1, 14, 0, 0, 0, time.UTC)},
+		{name: "summer", value: "2024-01-15 10:00:00", expected: time.Date(2024, time.January, 15, 13, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := timecl.ParseInZone("2006-01-02 15:04:05", tt.value)
+			if err != nil {
+				t.Fatalf("parsing: %s", err)
+			}
+
+			if !got.Equal(tt.expected) {
+				t.Fatalf("expected %s, got %s", tt.expected, got.UTC())
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	// 02:00 UTC is still the previous day in Chile.
+	v := time.Date(2024, time.June, 1, 2, 0, 0, 0, time.UTC)
+
+	if got, exp := timecl.FormatDate(v), "2024-05-31"; got != exp {
+		t.Errorf("expected date %s, got %s", exp, got)
+	}
+
+	if got, exp := timecl.FormatRFC3339(v), "2024-05-31T22:00:00-04:00"; got != exp {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+}
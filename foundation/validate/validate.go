@@ -17,6 +17,13 @@ var validate *validator.Validate
 // translator is a cache of locale and translation information.
 var translator ut.Translator
 
+// Validatable represents a model that knows how to validate itself. Cores
+// call Validate before touching the database so input validation is
+// centralized in the domain.
+type Validatable interface {
+	Validate() error
+}
+
 func init() {
 
 	// Instantiate a validator.
@@ -49,17 +56,23 @@ func Check(val any) error {
 			return err
 		}
 
-		var fields FieldErrors
-		for _, verror := range verrors {
-			field := FieldError{
-				Field: verror.Field(),
-				Err:   verror.Translate(translator),
-			}
-			fields = append(fields, field)
-		}
-
-		return fields
+		return FromValidationErrors(verrors)
 	}
 
 	return nil
 }
+
+// FromValidationErrors maps the errors reported by the validator to field
+// errors, using the JSON names of the fields and the english messages.
+func FromValidationErrors(verrors validator.ValidationErrors) FieldErrors {
+	fields := make(FieldErrors, 0, len(verrors))
+	for _, verror := range verrors {
+		field := FieldError{
+			Field: verror.Field(),
+			Err:   verror.Translate(translator),
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompressMinBytes is the size from which response bodies are
+// compressed when no threshold is configured.
+const DefaultCompressMinBytes = 1024
+
+// Compression configures the compression of the response bodies written by
+// Respond. Bodies are compressed with gzip or deflate, following the
+// Accept-Encoding header of the request, when they have at least MinBytes.
+type Compression struct {
+	Enabled  bool
+	MinBytes int
+}
+
+// SetCompression sets the compression of the response bodies. It should be
+// called once at startup, before registering routes.
+func (a *App) SetCompression(c Compression) {
+	if c.MinBytes <= 0 {
+		c.MinBytes = DefaultCompressMinBytes
+	}
+
+	a.compression = c
+}
+
+// negotiateEncoding returns the content encoding accepted by the request,
+// preferring gzip, or an empty string when none is supported. A coding with
+// a quality of zero is refused, and "*" accepts the codings that aren't
+// listed.
+func negotiateEncoding(r *http.Request) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		accepted[coding] = quality(params) > 0
+	}
+
+	for _, coding := range []string{"gzip", "deflate"} {
+		if ok, listed := accepted[coding]; listed {
+			if ok {
+				return coding
+			}
+			continue
+		}
+
+		if accepted["*"] {
+			return coding
+		}
+	}
+
+	return ""
+}
+
+// quality returns the q parameter found in the params of a coding, 1 when
+// there is none and 0 when it can't be parsed.
+func quality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+
+		return q
+	}
+
+	return 1
+}
+
+// compress returns the data compressed with the encoding. The deflate
+// encoding is the zlib format, as defined for HTTP, not raw deflate.
+func compress(encoding string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var zw io.WriteCloser
+	switch encoding {
+	case "gzip":
+		zw = gzip.NewWriter(&buf)
+	default:
+		zw = zlib.NewWriter(&buf)
+	}
+
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,97 @@
+package web_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestCompression(t *testing.T) {
+	large := map[string]string{"data": strings.Repeat("a", 2048)}
+	small := map[string]string{"data": "a"}
+
+	app := web.NewApp(nil)
+	app.SetCompression(web.Compression{Enabled: true, MinBytes: 1024})
+	app.Handle(http.MethodGet, "", "/large", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, large, http.StatusOK)
+	})
+	app.Handle(http.MethodGet, "", "/small", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, small, http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		encoding       string
+	}{
+		{name: "gzip", path: "/large", acceptEncoding: "gzip", encoding: "gzip"},
+		{name: "gzip preferred", path: "/large", acceptEncoding: "deflate, gzip", encoding: "gzip"},
+		{name: "deflate", path: "/large", acceptEncoding: "deflate", encoding: "deflate"},
+		{name: "gzip refused", path: "/large", acceptEncoding: "gzip;q=0, deflate", encoding: "deflate"},
+		{name: "gzip refused with spaces", path: "/large", acceptEncoding: "gzip ; q=0.0, deflate", encoding: "deflate"},
+		{name: "any", path: "/large", acceptEncoding: "*", encoding: "gzip"},
+		{name: "any but gzip", path: "/large", acceptEncoding: "gzip;q=0, *", encoding: "deflate"},
+		{name: "all refused", path: "/large", acceptEncoding: "gzip;q=0, deflate;q=0"},
+		{name: "unsupported", path: "/large", acceptEncoding: "br"},
+		{name: "none", path: "/large"},
+		{name: "below threshold", path: "/small", acceptEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Fatalf("expected encoding %q, got %q", tt.encoding, got)
+			}
+
+			if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Fatalf("expected the response to vary on Accept-Encoding, got %q", got)
+			}
+
+			var body io.Reader = w.Body
+			switch tt.encoding {
+			case "gzip":
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("reading gzip: %s", err)
+				}
+				body = zr
+			case "deflate":
+				zr, err := zlib.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("reading zlib: %s", err)
+				}
+				body = zr
+			}
+
+			data, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("decoding body: %s", err)
+			}
+
+			want := `{"data":"a"}`
+			if tt.path == "/large" {
+				want = `{"data":"` + strings.Repeat("a", 2048) + `"}`
+			}
+
+			if string(data) != want {
+				t.Fatalf("expected the original body after decoding, got %d bytes", len(data))
+			}
+		})
+	}
+}
@@ -2,17 +2,31 @@ package web
 
 import (
 	"context"
+	"io"
 	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/mask"
+	"github.com/Yeremi528/laboratorio/foundation/semver"
 )
 
 type contextKey int
 
 const ctxKey contextKey = 1
+const logKey contextKey = 2
 const defaultTraceID = "00000000-0000-000000000000"
 
+// DefaultLocale is the locale returned by GetLocale when none was set.
+const DefaultLocale = "es"
+
+// noopLogger is returned by LoggerFromContext when no logger has been bound
+// to the context.
+var noopLogger = logger.New(io.Discard, logger.LevelError, "", nil)
+
 // Values struct represents the state for each request.
 type Values struct {
 	TraceID       string
+	ParentSpanID  string
 	Now           time.Time
 	StatusCode    int
 	Response      string
@@ -21,6 +35,16 @@ type Values struct {
 	SecurityToken string
 	DeviceID      string
 	Token         string
+	Roles         []string
+	Locale        string
+
+	defaultLocale    string
+	masker           *mask.Masker
+	format           string
+	compress         bool
+	encoding         string
+	compressMinBytes int
+	store            *store
 }
 
 /*
@@ -60,6 +84,17 @@ func GetTime(ctx context.Context) time.Time {
 	return v.Now
 }
 
+// SetParentSpanID sets the ID of the caller span into the context, so logs
+// can be correlated with the span that made the request.
+func SetParentSpanID(ctx context.Context, spanID string) {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok {
+		return
+	}
+
+	v.ParentSpanID = spanID
+}
+
 // SetStatusCode sets the status code back into the context.
 func SetStatusCode(ctx context.Context, statusCode int) {
 	v, ok := ctx.Value(ctxKey).(*Values)
@@ -100,6 +135,23 @@ func SetDeviceVersion(ctx context.Context, version string) {
 	v.DeviceVersion = version
 }
 
+// GetDeviceVersionSemver returns the user's Device Version from the context
+// parsed as a semantic version. It returns false when the version is not set
+// or is not a semantic version.
+func GetDeviceVersionSemver(ctx context.Context) (semver.Version, bool) {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok || v.DeviceVersion == "" {
+		return semver.Version{}, false
+	}
+
+	ver, err := semver.Parse(v.DeviceVersion)
+	if err != nil {
+		return semver.Version{}, false
+	}
+
+	return ver, true
+}
+
 func SetSecurityToken(ctx context.Context, securityToken string) {
 	v, ok := ctx.Value(ctxKey).(*Values)
 	if !ok {
@@ -127,3 +179,84 @@ func SetToken(ctx context.Context, token string) {
 	v.Token = token
 
 }
+
+// SetRoles sets the roles of the authenticated user into the context.
+func SetRoles(ctx context.Context, roles []string) {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok {
+		return
+	}
+
+	v.Roles = roles
+}
+
+// SetLocale sets the language of the request into the context.
+func SetLocale(ctx context.Context, locale string) {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok {
+		return
+	}
+
+	v.Locale = locale
+}
+
+// SetDefaultLocale sets the language GetLocale falls back to when the
+// request has none, like the default of the locale middleware.
+func SetDefaultLocale(ctx context.Context, locale string) {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok {
+		return
+	}
+
+	v.defaultLocale = locale
+}
+
+// GetLocale returns the language of the request from the context, falling
+// back to the default set with SetDefaultLocale, or to DefaultLocale when
+// none was set.
+func GetLocale(ctx context.Context) string {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	switch {
+	case !ok:
+		return DefaultLocale
+	case v.Locale != "":
+		return v.Locale
+	case v.defaultLocale != "":
+		return v.defaultLocale
+	}
+
+	return DefaultLocale
+}
+
+// TraceFields returns the trace ID, and the ID of the caller span when
+// known, of the request. The span of the request itself is added by the
+// logger as span_id. It's meant to be used as the required fields of the
+// logger so every record written within a request can be correlated.
+func TraceFields(ctx context.Context) []any {
+	v := GetValues(ctx)
+
+	fields := make([]any, 2, 4)
+	fields[0], fields[1] = "traceID", v.TraceID
+
+	if v.ParentSpanID != "" {
+		fields = append(fields, "parentSpanID", v.ParentSpanID)
+	}
+
+	return fields
+}
+
+// WithLogger stores the request scoped logger in the context.
+func WithLogger(ctx context.Context, log *logger.Logger) context.Context {
+	return context.WithValue(ctx, logKey, log)
+}
+
+// LoggerFromContext returns the logger bound to the context. A logger that
+// discards every record is returned when none has been set.
+func LoggerFromContext(ctx context.Context) *logger.Logger {
+	log, ok := ctx.Value(logKey).(*logger.Logger)
+	if !ok || log == nil {
+		return noopLogger
+	}
+
+	return log
+}
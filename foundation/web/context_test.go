@@ -0,0 +1,80 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/semver"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestLoggerFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+
+	ctx := web.WithLogger(context.Background(), log)
+
+	if got := web.LoggerFromContext(ctx); got != log {
+		t.Fatal("expected the logger bound to the context")
+	}
+
+	web.LoggerFromContext(ctx).Info(ctx, "hello")
+
+	if !strings.Contains(buf.String(), `"message":"hello"`) {
+		t.Fatalf("expected the record to be written, got %s", buf.String())
+	}
+}
+
+func TestLoggerFromContextFallback(t *testing.T) {
+	log := web.LoggerFromContext(context.Background())
+	if log == nil {
+		t.Fatal("expected a no-op logger when none is bound")
+	}
+
+	// The fallback must be safe to use.
+	log.Error(context.Background(), "discarded")
+}
+
+func TestGetDeviceVersionSemver(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		ok      bool
+	}{
+		{name: "valid", version: "1.10.0", ok: true},
+		{name: "missing", version: "", ok: false},
+		{name: "malformed", version: "one.two", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got semver.Version
+				ok  bool
+			)
+
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				web.SetDeviceVersion(ctx, tt.version)
+				got, ok = web.GetDeviceVersionSemver(ctx)
+				return nil
+			}
+
+			app := web.NewApp(nil)
+			app.Handle(http.MethodGet, "", "/", handler)
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if ok != tt.ok {
+				t.Fatalf("expected ok %t, got %t", tt.ok, ok)
+			}
+
+			if ok && got.String() != tt.version {
+				t.Fatalf("expected %s, got %s", tt.version, got)
+			}
+		})
+	}
+}
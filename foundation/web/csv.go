@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// csvFlushRows is the number of rows written between flushes, so large
+// exports reach the client while they are written.
+const csvFlushRows = 100
+
+// RespondCSV sends the rows as a CSV attachment named filename, with the
+// headers as the first record. The rows are flushed to the client as they
+// are written instead of buffering the whole document. Like the other
+// streamed responses, it's not recorded in the context.
+//
+// Cells starting with =, +, -, @, a tab or a carriage return are prefixed
+// with a single quote so spreadsheets don't evaluate them as formulas.
+//
+// A client that goes away midway isn't an error: broken pipe and
+// connection reset errors are swallowed, like the App does with the
+// shutdown errors.
+func RespondCSV(ctx context.Context, w http.ResponseWriter, filename string, headers []string, rows [][]string) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+
+	SetStatusCode(ctx, http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	cw := csv.NewWriter(w)
+
+	flush := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+
+		// Writers that can't flush, like some test recorders, just keep
+		// the data buffered.
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+
+		return nil
+	}
+
+	if len(headers) > 0 {
+		if err := cw.Write(csvRecord(headers)); err != nil {
+			return csvWriteError(err)
+		}
+	}
+
+	for i, row := range rows {
+		if err := cw.Write(csvRecord(row)); err != nil {
+			return csvWriteError(err)
+		}
+
+		if (i+1)%csvFlushRows == 0 {
+			if err := flush(); err != nil {
+				return csvWriteError(err)
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return csvWriteError(err)
+	}
+
+	return nil
+}
+
+// csvRecord returns the cells of the record with the ones a spreadsheet
+// would take for a formula escaped. The record is only copied when a cell
+// needs it.
+func csvRecord(record []string) []string {
+	escaped := record
+	copied := false
+
+	for i, cell := range record {
+		if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			continue
+		}
+
+		if !copied {
+			escaped = slices.Clone(record)
+			copied = true
+		}
+		escaped[i] = "'" + cell
+	}
+
+	return escaped
+}
+
+// csvWriteError swallows the errors caused by the client closing the
+// connection.
+func csvWriteError(err error) error {
+	if isClientGone(err) {
+		return nil
+	}
+
+	return fmt.Errorf("write csv: %w", err)
+}
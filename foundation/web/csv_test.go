@@ -0,0 +1,113 @@
+package web_test
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"syscall"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// flushRecorder counts the flushes on top of a ResponseRecorder.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (fr *flushRecorder) Flush() {
+	fr.flushes++
+	fr.ResponseRecorder.Flush()
+}
+
+func TestRespondCSV(t *testing.T) {
+	rows := make([][]string, 250)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("user%d", i), "Rojas, Ana"}
+	}
+
+	w := flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	if err := web.RespondCSV(context.Background(), &w, "usuarios 2026.csv", []string{"id", "name"}, rows); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %s", ct)
+	}
+
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="usuarios 2026.csv"` {
+		t.Fatalf("unexpected content disposition %s", cd)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("expected a valid CSV: %s", err)
+	}
+
+	if len(records) != len(rows)+1 || records[1][1] != "Rojas, Ana" {
+		t.Fatalf("expected the header and %d rows, got %d records", len(rows), len(records))
+	}
+
+	// Every 100 rows and once at the end.
+	if w.flushes != 3 {
+		t.Fatalf("expected 3 flushes, got %d", w.flushes)
+	}
+}
+
+// brokenWriter fails every write like a client that closed the connection.
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (bw brokenWriter) Write(p []byte) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+func TestRespondCSVFormulas(t *testing.T) {
+	rows := [][]string{
+		{"=HYPERLINK(\"http://evil.example\")", "+56 9 1234 5678", "-1", "@SUM(A1)", "\tcmd", "Ana", "a=b", ""},
+	}
+
+	w := httptest.NewRecorder()
+	if err := web.RespondCSV(context.Background(), w, "users.csv", []string{"=name"}, rows); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cr := csv.NewReader(w.Body)
+	cr.FieldsPerRecord = -1
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		t.Fatalf("expected a valid CSV: %s", err)
+	}
+
+	if records[0][0] != "'=name" {
+		t.Fatalf("expected the header to be escaped, got %q", records[0][0])
+	}
+
+	expected := []string{"'=HYPERLINK(\"http://evil.example\")", "'+56 9 1234 5678", "'-1", "'@SUM(A1)", "'\tcmd", "Ana", "a=b", ""}
+	if !slices.Equal(records[1], expected) {
+		t.Fatalf("expected %q, got %q", expected, records[1])
+	}
+
+	if rows[0][0] != "=HYPERLINK(\"http://evil.example\")" {
+		t.Fatalf("expected the rows of the caller to be left untouched, got %q", rows[0][0])
+	}
+}
+
+func TestRespondCSVBrokenPipe(t *testing.T) {
+	w := brokenWriter{ResponseRecorder: httptest.NewRecorder()}
+
+	if err := web.RespondCSV(context.Background(), w, "users.csv", nil, [][]string{{"a"}}); err != nil {
+		t.Fatalf("expected the broken pipe to be swallowed, got %v", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the status to be sent, got %d", w.Code)
+	}
+}
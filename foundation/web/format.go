@@ -0,0 +1,107 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Set of content types Respond can encode the responses in.
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeXML  = "application/xml"
+)
+
+// encoders maps the content types to the function encoding the responses in
+// them. A new format only needs an entry here to be available to SetFormats.
+var encoders = map[string]func(v any) ([]byte, error){
+	ContentTypeJSON: json.Marshal,
+	ContentTypeXML:  xml.Marshal,
+}
+
+// SetFormats enables the content types, besides JSON, that Respond can
+// encode the responses in, following the Accept header of the request. JSON
+// remains the default. Content types without an encoder are ignored. It
+// should be called once at startup, before registering routes.
+func (a *App) SetFormats(contentTypes ...string) {
+	a.formats = a.formats[:0]
+	for _, ct := range contentTypes {
+		if _, exists := encoders[ct]; exists && ct != ContentTypeJSON {
+			a.formats = append(a.formats, ct)
+		}
+	}
+}
+
+// acceptedType is a media type of the Accept header with its quality value.
+type acceptedType struct {
+	mediaType string
+	q         float64
+}
+
+// negotiateFormat returns the content type among the formats with the
+// highest quality value in the Accept header, or JSON when none matches.
+func negotiateFormat(r *http.Request, formats []string) string {
+	if len(formats) == 0 {
+		return ContentTypeJSON
+	}
+
+	var accepted []acceptedType
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		if q > 0 {
+			accepted = append(accepted, acceptedType{mediaType: strings.ToLower(mediaType), q: q})
+		}
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].q > accepted[j].q
+	})
+
+	for _, at := range accepted {
+		if at.mediaType == ContentTypeJSON {
+			return ContentTypeJSON
+		}
+
+		for _, f := range formats {
+			if at.mediaType == f {
+				return f
+			}
+		}
+	}
+
+	return ContentTypeJSON
+}
+
+// encodeResponse encodes the data in the content type negotiated for the
+// request, falling back to JSON outside of an App or when the data can't be
+// encoded in that format, like maps in XML.
+func encodeResponse(ctx context.Context, data any) (string, []byte, error) {
+	if v, ok := ctx.Value(ctxKey).(*Values); ok && v.format != "" && v.format != ContentTypeJSON {
+		if body, err := encoders[v.format](data); err == nil {
+			return v.format, body, nil
+		}
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return ContentTypeJSON, body, nil
+}
@@ -0,0 +1,93 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+
+	record := func(name string) web.Middleware {
+		return func(handler web.Handler) web.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				calls = append(calls, name+" before")
+				err := handler(ctx, w, r)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	app := web.NewApp(nil, record("app1"), record("app2"))
+	app.Handle(http.MethodGet, "", "/", handler, record("route"))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	expected := []string{
+		"app1 before", "app2 before", "route before",
+		"handler",
+		"route after", "app2 after", "app1 after",
+	}
+
+	if !slices.Equal(calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+}
+
+func TestGroupMiddlewareOrder(t *testing.T) {
+	var calls []string
+
+	record := func(name string) web.Middleware {
+		return func(handler web.Handler) web.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				calls = append(calls, name)
+				return handler(ctx, w, r)
+			}
+		}
+	}
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	app := web.NewApp(nil, record("app"))
+	users := app.Group("/v1/users/", record("group"))
+	users.Handle(http.MethodGet, "/{user_id}", handler, record("route"))
+	users.Group("admin", record("admin")).Handle(http.MethodGet, "/stats", handler, record("route"))
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "/v1/users/42", expected: []string{"app", "group", "route", "handler"}},
+		{path: "/v1/users/admin/stats", expected: []string{"app", "group", "admin", "route", "handler"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			calls = nil
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected the route to match, got %d", w.Code)
+			}
+
+			if !slices.Equal(calls, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ErrMissingParam is returned when a required parameter is not present.
+var ErrMissingParam = errors.New("parameter is required")
+
+// Param returns a parameter value from the request.
+func Param(r *http.Request, key string) string {
+	s := chi.URLParamFromCtx(r.Context(), key)
+
+	return s
+}
+
+// QueryInt returns the query parameter as an int. The default is returned
+// when the parameter is absent and a validate.FieldErrors when it isn't a
+// number.
+func QueryInt(r *http.Request, key string, def int) (int, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, validate.NewFieldsError(key, errors.New("must be a number"))
+	}
+
+	return n, nil
+}
+
+// QueryUUID returns the query parameter as a uuid. A validate.FieldErrors is
+// returned when the parameter is absent or isn't a valid uuid.
+func QueryUUID(r *http.Request, key string) (uuid.UUID, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return uuid.UUID{}, validate.NewFieldsError(key, ErrMissingParam)
+	}
+
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.UUID{}, validate.NewFieldsError(key, errors.New("must be a valid uuid"))
+	}
+
+	return id, nil
+}
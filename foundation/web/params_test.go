@@ -0,0 +1,89 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/google/uuid"
+)
+
+func TestParam(t *testing.T) {
+	var got string
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		got = web.Param(r, "id")
+		return nil
+	}
+
+	app := web.NewApp(nil)
+	app.Handle(http.MethodGet, "", "/users/{id}", handler)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if got != "42" {
+		t.Fatalf("expected 42, got %q", got)
+	}
+}
+
+func TestQueryInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+		fails    bool
+	}{
+		{name: "present", query: "n=7", expected: 7},
+		{name: "absent", query: "", expected: 10},
+		{name: "not a number", query: "n=seven", fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+
+			got, err := web.QueryInt(r, "n", 10)
+			if tt.fails {
+				if !validate.IsFieldErrors(err) {
+					t.Fatalf("expected field errors, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestQueryUUID(t *testing.T) {
+	id := uuid.New()
+
+	r := httptest.NewRequest(http.MethodGet, "/?id="+id.String(), nil)
+	got, err := web.QueryUUID(r, "id")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got != id {
+		t.Fatalf("expected %s, got %s", id, got)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = web.QueryUUID(r, "id")
+	if fields := validate.GetFieldErrors(err); len(fields) != 1 || fields[0].Err != web.ErrMissingParam.Error() {
+		t.Fatalf("expected a missing parameter error, got %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?id=nope", nil)
+	if _, err := web.QueryUUID(r, "id"); !validate.IsFieldErrors(err) {
+		t.Fatalf("expected field errors for an invalid uuid, got %v", err)
+	}
+}
@@ -2,20 +2,40 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"reflect"
 
-	"github.com/go-chi/chi/v5"
+	"github.com/Yeremi528/laboratorio/foundation/validate"
 )
 
-type validator interface {
-	Validate() error
+// DefaultMaxBytes is the maximum size of a request body read by Decode.
+const DefaultMaxBytes = 1 << 20
+
+// ErrEmptyBody is returned by Decode when the request has no body.
+var ErrEmptyBody = errors.New("request body is empty")
+
+// RequestError is returned by Decode when the body of the request can't be
+// decoded. Its message is safe to be shown to clients.
+type RequestError struct {
+	Err error
 }
 
-// Param returns a parameter value from the request.
-func Param(r *http.Request, key string) string {
-	s := chi.URLParamFromCtx(r.Context(), key)
+// Error implements the error interface.
+func (re *RequestError) Error() string {
+	return re.Err.Error()
+}
 
-	return s
+// Unwrap returns the underlying decoding error.
+func (re *RequestError) Unwrap() error {
+	return re.Err
+}
+
+// IsRequestError checks if an error of type RequestError exists.
+func IsRequestError(err error) bool {
+	var re *RequestError
+	return errors.As(err, &re)
 }
 
 // Decode reads the body of an HTTP request looking for a JSON document. The
@@ -23,13 +43,33 @@ func Param(r *http.Request, key string) string {
 // If the provided value is a struct then it is checked for validation tags.
 // If the value implements a validate function, it is executed.
 func Decode(r *http.Request, val any) error {
-	decoder := json.NewDecoder(r.Body)
+	return DecodeLimit(r, val, DefaultMaxBytes)
+}
+
+// DecodeLimit is like Decode but reads at most maxBytes from the body.
+// Decoding failures are returned as a RequestError and validation failures
+// as validate.FieldErrors.
+func DecodeLimit(r *http.Request, val any, maxBytes int64) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return &RequestError{Err: ErrEmptyBody}
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBytes))
 	decoder.DisallowUnknownFields()
 	if err := decoder.Decode(val); err != nil {
-		return err
+		if errors.Is(err, io.EOF) {
+			return &RequestError{Err: ErrEmptyBody}
+		}
+		return &RequestError{Err: err}
+	}
+
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Struct {
+		if err := validate.Check(val); err != nil {
+			return err
+		}
 	}
 
-	if v, ok := val.(validator); ok {
+	if v, ok := val.(validate.Validatable); ok {
 		if err := v.Validate(); err != nil {
 			return err
 		}
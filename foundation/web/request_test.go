@@ -0,0 +1,55 @@
+package web_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestDecodeLimit(t *testing.T) {
+	type newUser struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	tests := []struct {
+		name     string
+		body     io.Reader
+		check    func(err error) bool
+		expected string
+	}{
+		{name: "valid", body: strings.NewReader(`{"name":"Ana"}`), check: func(err error) bool { return err == nil }},
+		{name: "no body", check: func(err error) bool { return errors.Is(err, web.ErrEmptyBody) && web.IsRequestError(err) }, expected: "ErrEmptyBody"},
+		{name: "empty body", body: strings.NewReader(""), check: func(err error) bool { return errors.Is(err, web.ErrEmptyBody) && web.IsRequestError(err) }, expected: "ErrEmptyBody"},
+		{name: "too large", body: strings.NewReader(`{"name":"` + strings.Repeat("a", 64) + `"}`), check: func(err error) bool {
+			var mbe *http.MaxBytesError
+			return errors.As(err, &mbe) && mbe.Limit == 32 && web.IsRequestError(err)
+		}, expected: "MaxBytesError"},
+		{name: "unknown field", body: strings.NewReader(`{"name":"Ana","admin":true}`), check: func(err error) bool {
+			return web.IsRequestError(err) && strings.Contains(err.Error(), `unknown field "admin"`)
+		}, expected: "unknown field RequestError"},
+		{name: "invalid json", body: strings.NewReader(`{"name":`), check: web.IsRequestError, expected: "RequestError"},
+		{name: "validation", body: strings.NewReader(`{}`), check: func(err error) bool {
+			return validate.IsFieldErrors(err) && !web.IsRequestError(err)
+		}, expected: "FieldErrors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", tt.body)
+			if tt.body == nil {
+				r.Body = http.NoBody
+			}
+
+			var nu newUser
+			if err := web.DecodeLimit(r, &nu, 32); !tt.check(err) {
+				t.Fatalf("expected %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
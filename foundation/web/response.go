@@ -2,34 +2,99 @@ package web
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"strings"
 
 	"github.com/Yeremi528/laboratorio/foundation/mask"
 )
 
-// Respond converts the input data to JSON and sends it to the client.
+// Respond converts the input data to JSON, or to the format negotiated for the
+// request when the app has more formats enabled, and sends it to the client.
 func Respond(ctx context.Context, w http.ResponseWriter, data any, statusCode int) error {
 	if statusCode == http.StatusNoContent {
 		w.WriteHeader(statusCode)
+		SetStatusCode(ctx, statusCode)
 		return nil
 	}
 
-	jsonData, err := json.Marshal(data)
+	contentType, encoded, err := encodeResponse(ctx, data)
 	if err != nil {
 		return err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	return respondEncoded(ctx, w, data, contentType, encoded, statusCode)
+}
+
+// RespondCached is like Respond but supports conditional requests. The ETag
+// header is set to a strong ETag computed from the encoded document, and when a
+// GET or HEAD request already has it in If-None-Match a 304 without body is
+// sent instead.
+func RespondCached(ctx context.Context, w http.ResponseWriter, r *http.Request, data any, statusCode int) error {
+	if statusCode == http.StatusNoContent {
+		return Respond(ctx, w, data, statusCode)
+	}
+
+	contentType, encoded, err := encodeResponse(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	// A compressed body is a different representation, its ETag carries the
+	// encoding so caches don't mix it up with the identity one.
+	sum := sha256.Sum256(encoded)
+	etag := hex.EncodeToString(sum[:])
+	if encoding := responseEncoding(ctx, w, len(encoded)); encoding != "" {
+		etag += "-" + encoding
+	}
+	etag = `"` + etag + `"`
+	w.Header().Set("ETag", etag)
+
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		SetStatusCode(ctx, http.StatusNotModified)
+		return nil
+	}
+
+	return respondEncoded(ctx, w, data, contentType, encoded, statusCode)
+}
+
+// etagMatch reports whether the If-None-Match header value matches the etag.
+// The comparison is weak, as required for If-None-Match.
+func etagMatch(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// respondEncoded sends the encoded document and records the masked data as
+// the response of the request, whatever the format.
+func respondEncoded(ctx context.Context, w http.ResponseWriter, data any, contentType string, encoded []byte, statusCode int) error {
+	body, err := compressResponse(ctx, w, encoded)
+	if err != nil {
+		return err
+	}
+
+	if v, ok := ctx.Value(ctxKey).(*Values); ok && v.format != "" {
+		w.Header().Add("Vary", "Accept")
+	}
+
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(statusCode)
 
-	if _, err := w.Write(jsonData); err != nil {
+	if _, err := w.Write(body); err != nil {
 		return err
 	}
 
 	SetStatusCode(ctx, statusCode)
 
-	maskedResponse, err := mask.StructToByte(data)
+	maskedResponse, err := maskResponse(ctx, data)
 	if err != nil {
 		return nil
 	}
@@ -38,3 +103,52 @@ func Respond(ctx context.Context, w http.ResponseWriter, data any, statusCode in
 
 	return nil
 }
+
+// compressResponse compresses the data when compression is enabled for the
+// request and the data reaches the size threshold. Responses that already
+// have a Content-Encoding are left untouched. The response varies on
+// Accept-Encoding whenever compression is enabled, compressed or not, so
+// caches don't serve one encoding to a client asking for another.
+func compressResponse(ctx context.Context, w http.ResponseWriter, data []byte) ([]byte, error) {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok || !v.compress || w.Header().Get("Content-Encoding") != "" {
+		return data, nil
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	encoding := responseEncoding(ctx, w, len(data))
+	if encoding == "" {
+		return data, nil
+	}
+
+	compressed, err := compress(encoding, data)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+
+	return compressed, nil
+}
+
+// responseEncoding returns the encoding a body of n bytes is compressed
+// with, or an empty string when it's sent as is.
+func responseEncoding(ctx context.Context, w http.ResponseWriter, n int) string {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok || !v.compress || w.Header().Get("Content-Encoding") != "" || n < v.compressMinBytes {
+		return ""
+	}
+
+	return v.encoding
+}
+
+// maskResponse masks the data with the masker bound to the request, falling
+// back to the default masker outside of an App.
+func maskResponse(ctx context.Context, data any) ([]byte, error) {
+	if v, ok := ctx.Value(ctxKey).(*Values); ok && v.masker != nil {
+		return v.masker.StructToByte(data)
+	}
+
+	return mask.StructToByte(data)
+}
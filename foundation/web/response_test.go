@@ -0,0 +1,167 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestRespondCached(t *testing.T) {
+	var values web.Values
+
+	record := func(handler web.Handler) web.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			err := handler(ctx, w, r)
+			values = *web.GetValues(ctx)
+			return err
+		}
+	}
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.RespondCached(ctx, w, r, map[string]string{"name": "Ana"}, http.StatusOK)
+	}
+
+	app := web.NewApp(nil, record)
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Body.Len() == 0 {
+		t.Fatalf("expected a 200 with an ETag and a body, got %d, %q, %q", w.Code, etag, w.Body.String())
+	}
+
+	if values.Response == "" {
+		t.Fatal("expected the response to be recorded")
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		status      int
+	}{
+		{name: "match", ifNoneMatch: etag, status: http.StatusNotModified},
+		{name: "weak match", ifNoneMatch: `"other", W/` + etag, status: http.StatusNotModified},
+		{name: "any", ifNoneMatch: "*", status: http.StatusNotModified},
+		{name: "stale", ifNoneMatch: `"other"`, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values = web.Values{}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status || values.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d and %d recorded", tt.status, w.Code, values.StatusCode)
+			}
+
+			if w.Header().Get("ETag") != etag {
+				t.Fatalf("expected the ETag %s, got %s", etag, w.Header().Get("ETag"))
+			}
+
+			if tt.status == http.StatusNotModified && (w.Body.Len() != 0 || values.Response != "") {
+				t.Fatalf("expected no body and no recorded response for a 304, got %q, %q", w.Body.String(), values.Response)
+			}
+		})
+	}
+}
+
+func TestRespondCachedCompressed(t *testing.T) {
+	data := map[string]string{"data": strings.Repeat("a", 2048)}
+
+	app := web.NewApp(nil)
+	app.SetCompression(web.Compression{Enabled: true, MinBytes: 1024})
+	app.Handle(http.MethodGet, "", "/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.RespondCached(ctx, w, r, data, http.StatusOK)
+	})
+
+	send := func(acceptEncoding string, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		r.Header.Set("If-None-Match", ifNoneMatch)
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		return w
+	}
+
+	identity := send("", "").Header().Get("ETag")
+	gzipped := send("gzip", "").Header().Get("ETag")
+
+	if gzipped != strings.TrimSuffix(identity, `"`)+`-gzip"` {
+		t.Fatalf("expected the gzip ETag to carry the encoding, got %s and %s", identity, gzipped)
+	}
+
+	if w := send("gzip", gzipped); w.Code != http.StatusNotModified {
+		t.Fatalf("expected a 304 for the same encoding, got %d", w.Code)
+	}
+
+	if w := send("", gzipped); w.Code != http.StatusOK {
+		t.Fatalf("expected the identity body for the gzip ETag, got %d", w.Code)
+	}
+}
+
+func TestRespondFormats(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	var values web.Values
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		err := web.Respond(ctx, w, user{Name: "Ana"}, http.StatusOK)
+		values = *web.GetValues(ctx)
+		return err
+	}
+
+	app := web.NewApp(nil)
+	app.SetFormats(web.ContentTypeXML)
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{name: "default", accept: "", contentType: web.ContentTypeJSON, body: `{"name":"Ana"}`},
+		{name: "xml", accept: "application/xml", contentType: web.ContentTypeXML, body: `<user><name>Ana</name></user>`},
+		{name: "quality", accept: "application/xml;q=0.5, application/json", contentType: web.ContentTypeJSON, body: `{"name":"Ana"}`},
+		{name: "unsupported", accept: "text/csv", contentType: web.ContentTypeJSON, body: `{"name":"Ana"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values = web.Values{}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("expected content type %s, got %s", tt.contentType, ct)
+			}
+
+			if w.Body.String() != tt.body {
+				t.Fatalf("expected body %s, got %s", tt.body, w.Body.String())
+			}
+
+			if values.Response == "" {
+				t.Fatal("expected the masked response to be recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event represents a Server-Sent Event. Only the data is required.
+type Event struct {
+	ID   string
+	Name string
+	Data string
+}
+
+// lineBreaks removes the line breaks of the event fields that can't span
+// multiple lines.
+var lineBreaks = strings.NewReplacer("\r\n", "", "\r", "", "\n", "")
+
+// writeTo writes the event in the text/event-stream format. Every line of
+// the data is sent as a data field so clients join them back.
+func (e Event) writeTo(w io.Writer) error {
+	var b strings.Builder
+
+	if e.ID != "" {
+		b.WriteString("id: " + lineBreaks.Replace(e.ID) + "\n")
+	}
+
+	if e.Name != "" {
+		b.WriteString("event: " + lineBreaks.Replace(e.Name) + "\n")
+	}
+
+	data := strings.ReplaceAll(strings.ReplaceAll(e.Data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+
+	b.WriteString("\n")
+
+	_, err := w.Write([]byte(b.String()))
+	return err
+}
+
+// RespondSSE sends the events to the client as Server-Sent Events, flushing
+// every one of them as soon as it's received. It returns when the events
+// channel is closed or the context is cancelled, which happens when the
+// client disconnects. Like the other streamed responses, it's not recorded
+// in the context.
+//
+// The write deadline of the server only makes sense for requests, so it's
+// cleared for the stream. A client that goes away midway isn't an error,
+// broken pipe and connection reset errors are swallowed.
+func RespondSSE(ctx context.Context, w http.ResponseWriter, events <-chan Event) error {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("clear write deadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	SetStatusCode(ctx, http.StatusOK)
+
+	flush := func() error {
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	if err := flush(); err != nil {
+		return sseWriteError(err)
+	}
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if err := ev.writeTo(w); err != nil {
+				return sseWriteError(err)
+			}
+
+			if err := flush(); err != nil {
+				return sseWriteError(err)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// sseWriteError swallows the errors caused by the client closing the
+// connection.
+func sseWriteError(err error) error {
+	if isClientGone(err) {
+		return nil
+	}
+
+	return fmt.Errorf("write sse: %w", err)
+}
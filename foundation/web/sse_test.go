@@ -0,0 +1,70 @@
+package web_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestRespondSSE(t *testing.T) {
+	events := make(chan web.Event, 2)
+	events <- web.Event{ID: "1", Name: "notification", Data: "hola"}
+	events <- web.Event{Data: "line one\nline two"}
+	close(events)
+
+	w := flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	if err := web.RespondSSE(context.Background(), &w, events); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %s", ct)
+	}
+
+	want := "id: 1\nevent: notification\ndata: hola\n\ndata: line one\ndata: line two\n\n"
+	if w.Body.String() != want {
+		t.Fatalf("expected body %q, got %q", want, w.Body.String())
+	}
+
+	// Once for the headers and once per event.
+	if w.flushes != 3 {
+		t.Fatalf("expected 3 flushes, got %d", w.flushes)
+	}
+}
+
+func TestRespondSSEStops(t *testing.T) {
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error)
+		go func() {
+			done <- web.RespondSSE(ctx, httptest.NewRecorder(), make(chan web.Event))
+		}()
+
+		cancel()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the stream to stop")
+		}
+	})
+
+	t.Run("client gone", func(t *testing.T) {
+		events := make(chan web.Event, 1)
+		events <- web.Event{Data: "hola"}
+
+		w := brokenWriter{ResponseRecorder: httptest.NewRecorder()}
+
+		if err := web.RespondSSE(context.Background(), w, events); err != nil {
+			t.Fatalf("expected the broken pipe to be swallowed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"context"
+	"sync"
+)
+
+// store holds arbitrary request scoped values. It's shared by the handlers
+// and middleware of a request, which may run in different goroutines.
+type store struct {
+	mu     sync.RWMutex
+	values map[any]any
+}
+
+// SetValue stores a request scoped value under the key, so handlers and
+// middleware can share data without adding fields to Values. Use an
+// unexported key type, like for context values, to avoid collisions. The
+// value is discarded when the context doesn't belong to a request.
+func SetValue[T any](ctx context.Context, key any, val T) {
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok || v.store == nil {
+		return
+	}
+
+	v.store.mu.Lock()
+	defer v.store.mu.Unlock()
+
+	if v.store.values == nil {
+		v.store.values = make(map[any]any)
+	}
+	v.store.values[key] = val
+}
+
+// GetValue returns the request scoped value stored under the key. It returns
+// false when the key is not set or its value is not of type T.
+func GetValue[T any](ctx context.Context, key any) (T, bool) {
+	var zero T
+
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok || v.store == nil {
+		return zero, false
+	}
+
+	v.store.mu.RLock()
+	defer v.store.mu.RUnlock()
+
+	val, ok := v.store.values[key].(T)
+	if !ok {
+		return zero, false
+	}
+
+	return val, true
+}
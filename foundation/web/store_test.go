@@ -0,0 +1,64 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+type tenantKey struct{}
+
+type tenant struct {
+	ID string
+}
+
+func TestValueStore(t *testing.T) {
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if _, ok := web.GetValue[tenant](ctx, tenantKey{}); ok {
+			t.Error("expected no value before it's set")
+		}
+
+		web.SetValue(ctx, tenantKey{}, tenant{ID: "acme"})
+
+		got, ok := web.GetValue[tenant](ctx, tenantKey{})
+		if !ok || got.ID != "acme" {
+			t.Errorf("expected the tenant, got %+v, %t", got, ok)
+		}
+
+		if _, ok := web.GetValue[string](ctx, tenantKey{}); ok {
+			t.Error("expected a value of another type not to be returned")
+		}
+
+		// The store is shared by the goroutines of the request.
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				web.SetValue(ctx, i, i)
+				web.GetValue[int](ctx, i)
+			}(i)
+		}
+		wg.Wait()
+
+		return nil
+	}
+
+	app := web.NewApp(nil)
+	app.Handle(http.MethodGet, "", "/", handler)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestValueStoreOutsideRequest(t *testing.T) {
+	ctx := context.Background()
+
+	web.SetValue(ctx, tenantKey{}, tenant{ID: "acme"})
+
+	if _, ok := web.GetValue[tenant](ctx, tenantKey{}); ok {
+		t.Fatal("expected no value outside of a request")
+	}
+}
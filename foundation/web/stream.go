@@ -0,0 +1,72 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RespondStream sends a JSON array to the client without holding it in
+// memory. The headers and the status code are written before fn is called
+// and every call to the encoder Encode method writes one element of the
+// array. The response is not recorded in the context since it's never fully
+// in memory.
+//
+// Once the status code is sent it can't be changed, so when fn returns an
+// error the array is closed with a trailing {"incomplete":true} element,
+// letting clients tell a failed stream apart from a complete one. The error
+// is returned to be logged by the error handling, which knows from the
+// status code set in the context that the response was sent.
+func RespondStream(ctx context.Context, w http.ResponseWriter, statusCode int, fn func(enc *json.Encoder) error) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	SetStatusCode(ctx, statusCode)
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return fmt.Errorf("write stream: %w", err)
+	}
+
+	ew := elementWriter{w: w}
+	if err := fn(json.NewEncoder(&ew)); err != nil {
+		if err := json.NewEncoder(&ew).Encode(streamIncomplete{Incomplete: true, Error: "stream interrupted"}); err == nil {
+			io.WriteString(w, "]")
+		}
+
+		return fmt.Errorf("stream interrupted: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "]"); err != nil {
+		return fmt.Errorf("write stream: %w", err)
+	}
+
+	return nil
+}
+
+// streamIncomplete is the trailing element of a stream interrupted by an
+// error.
+type streamIncomplete struct {
+	Incomplete bool   `json:"incomplete"`
+	Error      string `json:"error"`
+}
+
+// elementWriter separates with a comma the array elements written by the
+// json encoder, which writes each encoded value in a single call.
+type elementWriter struct {
+	w       io.Writer
+	written bool
+}
+
+// Write implements the io.Writer interface.
+func (ew *elementWriter) Write(p []byte) (int, error) {
+	if ew.written {
+		if _, err := io.WriteString(ew.w, ","); err != nil {
+			return 0, err
+		}
+	}
+	ew.written = true
+
+	return ew.w.Write(p)
+}
@@ -0,0 +1,80 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/logger"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestRespondStream(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := web.RespondStream(context.Background(), w, http.StatusOK, func(enc *json.Encoder) error {
+		for i := 1; i <= 3; i++ {
+			if err := enc.Encode(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got []int
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected a valid JSON array, got %s: %s", w.Body, err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 elements, got %v", got)
+	}
+}
+
+func TestRespondStreamInterrupted(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", nil)
+	ctx := web.WithLogger(context.Background(), log)
+
+	errDB := errors.New("connection lost")
+
+	w := httptest.NewRecorder()
+	err := web.RespondStream(ctx, w, http.StatusOK, func(enc *json.Encoder) error {
+		if err := enc.Encode(map[string]int{"id": 1}); err != nil {
+			return err
+		}
+		return errDB
+	})
+	if !errors.Is(err, errDB) {
+		t.Fatalf("expected the stream error, got %v", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the status already sent, got %d", w.Code)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected a valid JSON array, got %s: %s", w.Body, err)
+	}
+
+	if len(got) != 2 || got[1]["incomplete"] != true {
+		t.Fatalf("expected a trailing incomplete marker, got %s", w.Body)
+	}
+
+	if strings.Contains(w.Body.String(), errDB.Error()) {
+		t.Fatalf("expected the error not to be sent to the client, got %s", w.Body)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected the failure to be left to the error handling to log, got %s", buf.String())
+	}
+}
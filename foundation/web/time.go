@@ -0,0 +1,50 @@
+package web
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TimeLayout is the format used to render times in API responses: RFC3339
+// in UTC with millisecond precision.
+const TimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Time represents a time value that is always marshaled using TimeLayout,
+// giving clients a stable format regardless of the precision of the value.
+// Response models should use it instead of time.Time.
+type Time struct {
+	time.Time
+}
+
+// NewTime constructs a Time value from a time.Time.
+func NewTime(t time.Time) Time {
+	return Time{Time: t}
+}
+
+// String returns the time formatted using TimeLayout.
+func (t Time) String() string {
+	return t.UTC().Format(TimeLayout)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Any RFC3339
+// value is accepted.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	v, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+
+	t.Time = v
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package web_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestTimeMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        time.Time
+		expected string
+	}{
+		{name: "whole second", t: time.Date(2024, time.June, 1, 10, 0, 0, 0, time.UTC), expected: `"2024-06-01T10:00:00.000Z"`},
+		{name: "nanoseconds", t: time.Date(2024, time.June, 1, 10, 0, 0, 123456789, time.UTC), expected: `"2024-06-01T10:00:00.123Z"`},
+		{name: "other zone", t: time.Date(2024, time.June, 1, 6, 0, 0, 5000000, time.FixedZone("GMT-4", -4*60*60)), expected: `"2024-06-01T10:00:00.005Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(web.NewTime(tt.t))
+			if err != nil {
+				t.Fatalf("marshaling: %s", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, data)
+			}
+
+			var got web.Time
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("unmarshaling: %s", err)
+			}
+
+			if !got.Equal(tt.t.Truncate(time.Millisecond)) {
+				t.Fatalf("expected %s after a round trip, got %s", tt.t, got.Time)
+			}
+		})
+	}
+}
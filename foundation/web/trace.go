@@ -0,0 +1,108 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Set of headers carrying the trace ID between services. Every service
+// reads them from the incoming requests, X-Trace-ID first, and sets both on
+// the requests to other services with PropagateTraceID.
+const (
+	// TraceIDHeader is the header used to receive the trace ID from upstream
+	// services and to return it to clients.
+	TraceIDHeader = "X-Trace-ID"
+
+	// TraceparentHeader is the W3C Trace Context header, used when the trace
+	// ID is 32 hex characters long, like a uuid without the dashes.
+	TraceparentHeader = "traceparent"
+)
+
+// Set of patterns used to validate the incoming trace IDs.
+var (
+	traceIDPattern     = regexp.MustCompile(`^[A-Za-z0-9_\-]{8,128}$`)
+	traceparentPattern = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+	traceIDHexPattern  = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// traceID returns the trace ID carried by the request in the X-Trace-ID or
+// the W3C traceparent header. A new one is generated when none of them is
+// present or valid.
+func traceID(r *http.Request) string {
+	if id := r.Header.Get(TraceIDHeader); traceIDPattern.MatchString(id) {
+		return id
+	}
+
+	if id, _ := traceparent(r); id != "" {
+		return id
+	}
+
+	return uuid.NewString()
+}
+
+// ParentSpanID returns the ID of the caller span carried by the W3C
+// traceparent header of the request. It returns an empty string when the
+// header is not present or valid.
+func ParentSpanID(r *http.Request) string {
+	_, spanID := traceparent(r)
+	return spanID
+}
+
+// traceparent returns the trace ID and the parent span ID of the W3C
+// traceparent header. Both are empty when the header is not present or
+// carries invalid all zero IDs.
+func traceparent(r *http.Request) (traceID string, spanID string) {
+	m := traceparentPattern.FindStringSubmatch(strings.TrimSpace(r.Header.Get(TraceparentHeader)))
+	if m == nil || m[1] == strings.Repeat("0", 32) || m[2] == strings.Repeat("0", 16) {
+		return "", ""
+	}
+
+	return m[1], m[2]
+}
+
+// PropagateTraceID sets the trace ID of the context on the request to
+// another service, in the X-Trace-ID header and in the W3C traceparent header
+// when the trace ID can be expressed in it, so the called service continues
+// the same trace. When the context carries an active span the traceparent is
+// injected by the otel propagator, so the called service links to that span.
+// Otherwise, trace IDs generated by this package are uuids, which fit the
+// traceparent header once the dashes are removed, and the traceparent header
+// carries a new span ID for the call. Nothing is set for a context without
+// request values.
+func PropagateTraceID(ctx context.Context, req *http.Request) {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	v, ok := ctx.Value(ctxKey).(*Values)
+	if !ok {
+		return
+	}
+
+	req.Header.Set(TraceIDHeader, v.TraceID)
+
+	if req.Header.Get(TraceparentHeader) != "" {
+		return
+	}
+
+	traceID := strings.ToLower(strings.ReplaceAll(v.TraceID, "-", ""))
+	if !traceIDHexPattern.MatchString(traceID) || traceID == strings.Repeat("0", 32) {
+		return
+	}
+
+	spanID := make([]byte, 8)
+	if _, err := rand.Read(spanID); err != nil {
+		return
+	}
+
+	req.Header.Set(TraceparentHeader, "00-"+traceID+"-"+hex.EncodeToString(spanID)+"-01")
+}
@@ -0,0 +1,185 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceID(t *testing.T) {
+	const traceparentID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tests := []struct {
+		name        string
+		traceID     string
+		traceparent string
+		expected    string
+	}{
+		{name: "trace id header", traceID: "trace-0001", expected: "trace-0001"},
+		{name: "traceparent", traceparent: "00-" + traceparentID + "-00f067aa0ba902b7-01", expected: traceparentID},
+		{name: "trace id header wins", traceID: "trace-0001", traceparent: "00-" + traceparentID + "-00f067aa0ba902b7-01", expected: "trace-0001"},
+		{name: "invalid trace id", traceID: "bad id\n"},
+		{name: "zero traceparent", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				got = web.GetTraceID(ctx)
+				return nil
+			}
+
+			app := web.NewApp(nil)
+			app.Handle(http.MethodGet, "", "/", handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.traceID != "" {
+				r.Header.Set(web.TraceIDHeader, tt.traceID)
+			}
+			if tt.traceparent != "" {
+				r.Header.Set("traceparent", tt.traceparent)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			switch tt.expected {
+			case "":
+				if _, err := uuid.Parse(got); err != nil {
+					t.Fatalf("expected a generated trace id, got %q", got)
+				}
+			default:
+				if got != tt.expected {
+					t.Fatalf("expected trace id %q, got %q", tt.expected, got)
+				}
+			}
+
+			if echoed := w.Header().Get(web.TraceIDHeader); echoed != got {
+				t.Fatalf("expected the trace id %q in the response, got %q", got, echoed)
+			}
+		})
+	}
+}
+
+func TestParentSpanID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	if got := web.ParentSpanID(r); got != "00f067aa0ba902b7" {
+		t.Fatalf("expected the parent span id, got %q", got)
+	}
+
+	r.Header.Set("traceparent", "garbage")
+	if got := web.ParentSpanID(r); got != "" {
+		t.Fatalf("expected no span id for an invalid header, got %q", got)
+	}
+}
+
+func TestPropagateTraceID(t *testing.T) {
+	const uuidTraceID = "4bf92f35-77b3-4da6-a3ce-929d0e0e4736"
+
+	tests := []struct {
+		name        string
+		traceID     string
+		traceparent string
+	}{
+		{name: "uuid", traceID: uuidTraceID, traceparent: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "custom", traceID: "trace-0001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out *http.Request
+
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				out = httptest.NewRequest(http.MethodGet, "http://users.internal/v1/users", nil)
+				web.PropagateTraceID(ctx, out)
+				return nil
+			}
+
+			app := web.NewApp(nil)
+			app.Handle(http.MethodGet, "", "/", handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(web.TraceIDHeader, tt.traceID)
+			app.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got := out.Header.Get(web.TraceIDHeader); got != tt.traceID {
+				t.Fatalf("expected the trace id %q, got %q", tt.traceID, got)
+			}
+
+			traceparent := out.Header.Get(web.TraceparentHeader)
+			if tt.traceparent == "" {
+				if traceparent != "" {
+					t.Fatalf("expected no traceparent, got %q", traceparent)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(traceparent, "00-"+tt.traceparent+"-") {
+				t.Fatalf("expected a traceparent for %s, got %q", tt.traceparent, traceparent)
+			}
+
+			// The called service continues the trace from the traceparent.
+			in := httptest.NewRequest(http.MethodGet, "/", nil)
+			in.Header.Set(web.TraceparentHeader, traceparent)
+			if web.ParentSpanID(in) == "" {
+				t.Fatalf("expected a valid traceparent, got %q", traceparent)
+			}
+		})
+	}
+}
+
+func TestPropagateTraceIDSpan(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	var out *http.Request
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		out = httptest.NewRequest(http.MethodGet, "http://users.internal/v1/users", nil)
+		web.PropagateTraceID(trace.ContextWithSpanContext(ctx, sc), out)
+		return nil
+	}
+
+	app := web.NewApp(nil)
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(web.TraceIDHeader, "trace-0001")
+	app.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got := out.Header.Get(web.TraceparentHeader); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("expected the traceparent of the active span, got %q", got)
+	}
+
+	if got := out.Header.Get(web.TraceIDHeader); got != "trace-0001" {
+		t.Fatalf("expected the trace id of the request, got %q", got)
+	}
+}
+
+func TestPropagateTraceIDNoValues(t *testing.T) {
+	out := httptest.NewRequest(http.MethodGet, "http://users.internal/v1/users", nil)
+	web.PropagateTraceID(context.Background(), out)
+
+	if out.Header.Get(web.TraceIDHeader) != "" || out.Header.Get(web.TraceparentHeader) != "" {
+		t.Fatalf("expected no trace headers outside of a request, got %v", out.Header)
+	}
+}
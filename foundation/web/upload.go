@@ -0,0 +1,147 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxUploadBytes is the maximum size of a file read by FormFile.
+const DefaultMaxUploadBytes = 10 << 20
+
+// sniffLen is the number of bytes http.DetectContentType looks at.
+const sniffLen = 512
+
+// FormFile returns the first file uploaded in the multipart field, of at
+// most DefaultMaxUploadBytes and of any content type.
+func FormFile(r *http.Request, field string) (multipart.File, *multipart.FileHeader, error) {
+	return FormFileLimit(r, field, DefaultMaxUploadBytes)
+}
+
+// FormFileLimit is like FormFile but accepts files of at most maxBytes and,
+// when provided, only of the allowed content types. The content type is
+// sniffed from the first bytes of the file instead of trusting the one sent
+// by the client, and it replaces the Content-Type of the returned header.
+// A file too large is reported with an http.MaxBytesError and a missing file
+// or a type not allowed as validate.FieldErrors.
+func FormFileLimit(r *http.Request, field string, maxBytes int64, allowed ...string) (multipart.File, *multipart.FileHeader, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil, &RequestError{Err: ErrEmptyBody}
+	}
+
+	// The limit covers the whole body, the other parts and the multipart
+	// boundaries are small in comparison.
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBytes+sniffLen)
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		return nil, nil, &RequestError{Err: err}
+	}
+
+	file, header, err := r.FormFile(field)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, nil, validate.NewFieldsError(field, ErrMissingParam)
+		}
+		return nil, nil, &RequestError{Err: err}
+	}
+
+	if header.Size > maxBytes {
+		file.Close()
+		return nil, nil, &RequestError{Err: &http.MaxBytesError{Limit: maxBytes}}
+	}
+
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("sniffing content type: %w", err)
+	}
+
+	if len(allowed) > 0 && !slices.Contains(allowed, contentType) {
+		file.Close()
+		return nil, nil, validate.NewFieldsError(field, fmt.Errorf("content type %s is not allowed", contentType))
+	}
+
+	header.Header.Set("Content-Type", contentType)
+
+	return file, header, nil
+}
+
+// sniffContentType detects the content type from the first bytes of the file
+// and rewinds it.
+func sniffContentType(file multipart.File) (string, error) {
+	buf := make([]byte, sniffLen)
+
+	n, err := io.ReadFull(file, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	// The parameters, like the charset, are not relevant to the guard.
+	contentType, _, _ := strings.Cut(http.DetectContentType(buf[:n]), ";")
+
+	return contentType, nil
+}
+
+// SaveUpload writes the uploaded file into dir and returns its path. The
+// file name sent by the client is sanitized, so it can't escape dir, and
+// prefixed with a random id, so uploads never overwrite each other.
+func SaveUpload(header *multipart.FileHeader, dir string) (string, error) {
+	src, err := header.Open()
+	if err != nil {
+		return "", fmt.Errorf("opening upload: %w", err)
+	}
+	defer src.Close()
+
+	path := filepath.Join(dir, uuid.NewString()+"-"+sanitizeFilename(header.Filename))
+
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", fmt.Errorf("creating file: %w", err)
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("writing file: %w", err)
+	}
+
+	if err := dst.Close(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("closing file: %w", err)
+	}
+
+	return path, nil
+}
+
+// sanitizeFilename keeps the base name of the file with only letters,
+// digits, dots, dashes and underscores.
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "upload"
+	}
+
+	return name
+}
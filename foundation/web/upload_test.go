@@ -0,0 +1,101 @@
+package web_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/validate"
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+// newUploadRequest returns a multipart request with the content in the
+// document field, sent with a misleading content type.
+func newUploadRequest(t *testing.T, filename string, content []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	part, err := mw.CreateFormFile("document", filename)
+	if err != nil {
+		t.Fatalf("creating form file: %s", err)
+	}
+	part.Write(content)
+	mw.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return r
+}
+
+func TestFormFileSaveUpload(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("%PDF-1.4\nsome document")
+
+	r := newUploadRequest(t, "../../etc/mi contrato.pdf", content)
+
+	file, header, err := web.FormFileLimit(r, "document", 1<<10, "application/pdf")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer file.Close()
+
+	if ct := header.Header.Get("Content-Type"); ct != "application/pdf" {
+		t.Fatalf("expected the sniffed content type, got %s", ct)
+	}
+
+	path, err := web.SaveUpload(header, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if filepath.Dir(path) != dir || !strings.HasSuffix(path, "-mi_contrato.pdf") {
+		t.Fatalf("expected a sanitized file name inside %s, got %s", dir, path)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading the upload: %s", err)
+	}
+
+	if !bytes.Equal(got, content) {
+		t.Fatalf("expected %q on disk, got %q", content, got)
+	}
+}
+
+func TestFormFileGuards(t *testing.T) {
+	t.Run("content type", func(t *testing.T) {
+		r := newUploadRequest(t, "fake.pdf", []byte("<html><body>not a pdf</body></html>"))
+
+		if _, _, err := web.FormFileLimit(r, "document", 1<<10, "application/pdf"); !validate.IsFieldErrors(err) {
+			t.Fatalf("expected field errors, got %v", err)
+		}
+	})
+
+	t.Run("size", func(t *testing.T) {
+		r := newUploadRequest(t, "big.txt", bytes.Repeat([]byte("a"), 2<<10))
+
+		_, _, err := web.FormFileLimit(r, "document", 1<<10)
+
+		var mbe *http.MaxBytesError
+		if !web.IsRequestError(err) || !errors.As(err, &mbe) {
+			t.Fatalf("expected a max bytes request error, got %v", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		r := newUploadRequest(t, "doc.txt", []byte("hello"))
+
+		if _, _, err := web.FormFile(r, "other"); !validate.IsFieldErrors(err) {
+			t.Fatalf("expected field errors, got %v", err)
+		}
+	})
+}
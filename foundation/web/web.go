@@ -6,11 +6,14 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/Yeremi528/laboratorio/foundation/mask"
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 )
 
 // Handler handles an http request.
@@ -19,19 +22,120 @@ type Handler func(ctx context.Context, w http.ResponseWriter, r *http.Request) e
 // App is the entrypoint of the app. It configures the context object for each http handler.
 type App struct {
 	*chi.Mux
-	shutdown chan os.Signal
-	mw       []Middleware
+	shutdown    chan os.Signal
+	mw          []Middleware
+	masker      *mask.Masker
+	compression Compression
+	formats     []string
+	draining    atomic.Bool
+	inFlight    atomic.Int64
 }
 
 // NewApp returns an App value that handles a set of routes for the app.
 func NewApp(shutdown chan os.Signal, mw ...Middleware) *App {
 	mux := chi.NewMux()
 
-	return &App{
+	app := App{
 		Mux:      mux,
 		shutdown: shutdown,
 		mw:       mw,
+		masker:   mask.New(),
 	}
+
+	app.NotFound(app.notFound)
+	app.MethodNotAllowed(app.methodNotAllowed)
+
+	return &app
+}
+
+// NotFound sets the handler for the requests that don't match any route.
+// Like CustomHandle, it runs without the app middleware, so the metrics and
+// traces are not keyed by arbitrary paths.
+func (a *App) NotFound(handler Handler, mw ...Middleware) {
+	a.Mux.NotFound(a.httpHandler(wrapMiddleware(mw, handler)))
+}
+
+// MethodNotAllowed sets the handler for the requests that match a route but
+// not its method. Like NotFound, it runs without the app middleware.
+func (a *App) MethodNotAllowed(handler Handler, mw ...Middleware) {
+	a.Mux.MethodNotAllowed(a.httpHandler(wrapMiddleware(mw, handler)))
+}
+
+// errorDocument mirrors the error envelope of the API responses.
+type errorDocument struct {
+	Error string `json:"error"`
+}
+
+// notFound is the default NotFound handler.
+func (a *App) notFound(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return Respond(ctx, w, errorDocument{Error: http.StatusText(http.StatusNotFound)}, http.StatusNotFound)
+}
+
+// routeMethods are the methods checked to fill the Allow header of a 405.
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// methodNotAllowed is the default MethodNotAllowed handler. It lists the
+// methods of the route in the Allow header, as chi's default handler does.
+func (a *App) methodNotAllowed(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	for _, method := range routeMethods {
+		if a.Mux.Match(chi.NewRouteContext(), method, r.URL.Path) {
+			w.Header().Add("Allow", method)
+		}
+	}
+
+	return Respond(ctx, w, errorDocument{Error: http.StatusText(http.StatusMethodNotAllowed)}, http.StatusMethodNotAllowed)
+}
+
+// SetMasker sets the masker used to mask the responses recorded for each
+// request. It should be called once at startup, before registering routes.
+func (a *App) SetMasker(m *mask.Masker) {
+	a.masker = m
+}
+
+// EnableCORS adds the CORS middleware to the app middleware and answers the
+// preflight requests before routing, since they would not match any route
+// otherwise. Requests that don't match a route keep getting a 404 or a 405.
+// It should be called once at startup, before registering routes.
+func (a *App) EnableCORS(mw Middleware) {
+	a.mw = append(a.mw, mw)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return Respond(ctx, w, nil, http.StatusNoContent)
+	}
+	preflight := a.httpHandler(wrapMiddleware([]Middleware{mw}, handler))
+
+	a.Mux.Use(func(next http.Handler) http.Handler {
+		h := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				preflight(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(h)
+	})
+}
+
+// Drain marks the app as draining, so readiness checks fail and the load
+// balancer stops routing new requests while in-flight ones complete.
+func (a *App) Drain() {
+	a.draining.Store(true)
+}
+
+// Draining reports whether the app has started draining.
+func (a *App) Draining() bool {
+	return a.draining.Load()
+}
+
+// InFlight returns the number of requests being handled. It's used when
+// shutting down to report the requests cut off by a forced close.
+func (a *App) InFlight() int64 {
+	return a.inFlight.Load()
 }
 
 // SignalShutdown is used to gracefully shutdown the app when an integrity
@@ -53,6 +157,43 @@ func (a *App) Handle(method, group, path string, handler Handler, mw ...Middlewa
 	a.handle(method, group, path, handler)
 }
 
+// Group represents a set of routes under a shared prefix and middleware.
+type Group struct {
+	app    *App
+	prefix string
+	mw     []Middleware
+}
+
+// Group returns a group for the routes under prefix. The group middleware
+// runs after the app middleware and before the middleware of each route.
+func (a *App) Group(prefix string, mw ...Middleware) *Group {
+	return &Group{
+		app:    a,
+		prefix: strings.Trim(prefix, "/"),
+		mw:     mw,
+	}
+}
+
+// Group returns a nested group under the prefix of g. Its middleware runs
+// after the middleware of g.
+func (g *Group) Group(prefix string, mw ...Middleware) *Group {
+	return &Group{
+		app:    g.app,
+		prefix: strings.Trim(g.prefix+"/"+strings.Trim(prefix, "/"), "/"),
+		mw:     append(slices.Clip(g.mw), mw...),
+	}
+}
+
+// Handle associates a handler function with the specified http method and
+// path under the group prefix.
+func (g *Group) Handle(method, path string, handler Handler, mw ...Middleware) {
+	handler = wrapMiddleware(mw, handler)
+	handler = wrapMiddleware(g.mw, handler)
+	handler = wrapMiddleware(g.app.mw, handler)
+
+	g.app.handle(method, g.prefix, path, handler)
+}
+
 // CustomHandle is similar to Handle function, but it requires you to specify explicitly
 // the desired middlewares. No app middlewares are set by default.
 func (a *App) CustomHandle(method, group, path string, handler Handler, mw ...Middleware) {
@@ -64,12 +205,36 @@ func (a *App) CustomHandle(method, group, path string, handler Handler, mw ...Mi
 }
 
 func (a *App) handle(method, group, path string, handler Handler) {
+	finalPath := path
+	if group != "" {
+		finalPath = "/" + group + path
+	}
+
+	a.Mux.MethodFunc(method, finalPath, a.httpHandler(handler))
+}
+
+// httpHandler adapts the handler to the http package, setting up the values
+// of the request in the context.
+func (a *App) httpHandler(handler Handler) http.HandlerFunc {
 	h := func(w http.ResponseWriter, r *http.Request) {
+		a.inFlight.Add(1)
+		defer a.inFlight.Add(-1)
 
-		// set trace id and init time for the incoming request.
-		v := Values{TraceID: uuid.NewString(), Now: time.Now().UTC()}
+		// set trace id and init time for the incoming request. The trace id
+		// is returned to the client so it can be reported.
+		v := Values{TraceID: traceID(r), Now: time.Now().UTC(), masker: a.masker, store: &store{}}
+		if len(a.formats) > 0 {
+			v.format = negotiateFormat(r, a.formats)
+		}
+		if a.compression.Enabled {
+			v.compress = true
+			v.encoding = negotiateEncoding(r)
+			v.compressMinBytes = a.compression.MinBytes
+		}
 		ctx := context.WithValue(r.Context(), ctxKey, &v)
 
+		w.Header().Set(TraceIDHeader, v.TraceID)
+
 		if err := handler(ctx, w, r); err != nil {
 			if validateShutdown(err) {
 				a.SignalShutdown()
@@ -78,7 +243,13 @@ func (a *App) handle(method, group, path string, handler Handler) {
 		}
 	}
 
-	a.Mux.MethodFunc(method, group+path, h)
+	return h
+}
+
+// isClientGone checks if the error was caused by writing to a client that
+// closed the connection.
+func isClientGone(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
 }
 
 // validateShutdown validates the error for special conditions that do not
@@ -116,6 +287,12 @@ func validateShutdown(err error) bool {
 		// packet instead of the TCP FIN, which is used to close a connection under normal
 		// circumstances.
 		return false
+
+	case isWebSocketClosed(err):
+
+		// The same goes for a WebSocket connection closed by the client
+		// while it's read or written.
+		return false
 	}
 
 	return true
@@ -0,0 +1,99 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+)
+
+func TestInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		<-release
+		return nil
+	}
+
+	app := web.NewApp(nil)
+	app.Handle(http.MethodGet, "", "/", handler)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	<-started
+	if n := app.InFlight(); n != 1 {
+		t.Fatalf("expected 1 request in flight, got %d", n)
+	}
+
+	close(release)
+	<-done
+
+	if n := app.InFlight(); n != 0 {
+		t.Fatalf("expected no request in flight, got %d", n)
+	}
+}
+
+func TestNotFoundMethodNotAllowed(t *testing.T) {
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	app := web.NewApp(nil)
+	app.Handle(http.MethodGet, "v1", "/users", handler)
+	app.Handle(http.MethodPost, "v1", "/users", handler)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		allow  []string
+	}{
+		{name: "unknown path", method: http.MethodGet, path: "/v1/nope", status: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodDelete, path: "/v1/users", status: http.StatusMethodNotAllowed, allow: []string{http.MethodGet, http.MethodPost}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, w.Code)
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("expected a JSON body, got %s", ct)
+			}
+
+			if w.Header().Get(web.TraceIDHeader) == "" {
+				t.Fatal("expected the trace id header")
+			}
+
+			var doc struct {
+				Error string `json:"error"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+				t.Fatalf("decoding: %s", err)
+			}
+
+			if doc.Error != http.StatusText(tt.status) {
+				t.Fatalf("expected the error %q, got %q", http.StatusText(tt.status), doc.Error)
+			}
+
+			if allow := w.Header().Values("Allow"); !slices.Equal(allow, tt.allow) {
+				t.Fatalf("expected Allow %v, got %v", tt.allow, allow)
+			}
+		})
+	}
+}
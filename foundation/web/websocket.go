@@ -0,0 +1,142 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// Set of intervals used to keep the WebSocket connections alive.
+const (
+	wsPingInterval = 30 * time.Second
+	wsPongTimeout  = 10 * time.Second
+)
+
+// Upgrade upgrades the request to a WebSocket connection. The trace id
+// header is sent with the handshake so the client can report it, and the
+// status code is set in the context so the error handling doesn't try to
+// respond once the connection is taken over. Pass the same context to
+// PumpWebSocket so the trace id is kept for the life of the connection.
+//
+// The deadlines of the server only make sense for requests, so they are
+// cleared from the connection and PumpWebSocket keeps it alive instead.
+func Upgrade(ctx context.Context, w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, fmt.Errorf("clear read deadline: %w", err)
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, fmt.Errorf("clear write deadline: %w", err)
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return nil, fmt.Errorf("websocket upgrade: %w", err)
+	}
+
+	SetStatusCode(ctx, http.StatusSwitchingProtocols)
+
+	return conn, nil
+}
+
+// PumpWebSocket reads the messages of the connection and calls fn with each
+// of them, while the messages received from out are written to the client.
+// A ping is sent every 30 seconds and the connection is closed when the
+// client doesn't answer in time. It returns when the client closes the
+// connection, when out is closed or when fn fails. The context passed to fn
+// is derived from ctx, so it carries the values of the request.
+//
+// The connection is closed when it returns. A connection closed normally by
+// either side isn't an error.
+func PumpWebSocket(ctx context.Context, conn *websocket.Conn, out <-chan []byte, fn func(ctx context.Context, msg []byte) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 3)
+
+	go func() {
+		for {
+			_, msg, err := conn.Read(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if err := fn(ctx, msg); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			select {
+			case msg, ok := <-out:
+				if !ok {
+					errs <- nil
+					return
+				}
+
+				if err := conn.Write(ctx, websocket.MessageText, msg); err != nil {
+					errs <- err
+					return
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				pingCtx, pingCancel := context.WithTimeout(ctx, wsPongTimeout)
+				err := conn.Ping(pingCtx)
+				pingCancel()
+
+				if err != nil {
+					errs <- fmt.Errorf("websocket keepalive: %w", err)
+					return
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	err := <-errs
+	cancel()
+
+	if err != nil && !isWebSocketClosed(err) {
+		conn.CloseNow()
+		return fmt.Errorf("websocket: %w", err)
+	}
+
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	return nil
+}
+
+// isWebSocketClosed checks if the error was caused by the WebSocket
+// connection being closed, normally or because the peer went away.
+func isWebSocketClosed(err error) bool {
+	switch websocket.CloseStatus(err) {
+	case websocket.StatusNormalClosure, websocket.StatusGoingAway, websocket.StatusNoStatusRcvd, websocket.StatusAbnormalClosure:
+		return true
+	}
+
+	return errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
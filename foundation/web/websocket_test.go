@@ -0,0 +1,118 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yeremi528/laboratorio/foundation/web"
+	"github.com/coder/websocket"
+)
+
+func TestWebSocketEcho(t *testing.T) {
+	traceIDs := make(chan string, 1)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		conn, err := web.Upgrade(ctx, w, r)
+		if err != nil {
+			return err
+		}
+
+		echo := func(ctx context.Context, msg []byte) error {
+			traceIDs <- web.GetTraceID(ctx)
+			return conn.Write(ctx, websocket.MessageText, msg)
+		}
+
+		return web.PumpWebSocket(ctx, conn, nil, echo)
+	}
+
+	shutdown := make(chan os.Signal, 1)
+	app := web.NewApp(shutdown)
+	app.Handle(http.MethodGet, "v1", "/ws", handler)
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/v1/ws", nil)
+	if err != nil {
+		t.Fatalf("dialing: %s", err)
+	}
+
+	traceID := resp.Header.Get(web.TraceIDHeader)
+	if traceID == "" {
+		t.Fatal("expected the trace id in the handshake")
+	}
+
+	if err := conn.Write(ctx, websocket.MessageText, []byte("hola")); err != nil {
+		t.Fatalf("writing: %s", err)
+	}
+
+	_, msg, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("reading: %s", err)
+	}
+
+	if string(msg) != "hola" {
+		t.Fatalf("expected the message to be echoed, got %q", msg)
+	}
+
+	if got := <-traceIDs; got != traceID {
+		t.Fatalf("expected the trace id %s in the connection context, got %s", traceID, got)
+	}
+
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	select {
+	case <-shutdown:
+		t.Fatal("expected a closed connection not to shut down the app")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebSocketClosedIsNotShutdown(t *testing.T) {
+	done := make(chan struct{})
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		defer close(done)
+
+		conn, err := web.Upgrade(ctx, w, r)
+		if err != nil {
+			return err
+		}
+
+		// The client closes the connection, the read fails.
+		_, _, err = conn.Read(ctx)
+		return err
+	}
+
+	shutdown := make(chan os.Signal, 1)
+	app := web.NewApp(shutdown)
+	app.Handle(http.MethodGet, "", "/ws", handler)
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dialing: %s", err)
+	}
+	conn.Close(websocket.StatusGoingAway, "")
+
+	<-done
+
+	select {
+	case <-shutdown:
+		t.Fatal("expected a closed connection not to shut down the app")
+	case <-time.After(100 * time.Millisecond):
+	}
+}